@@ -0,0 +1,188 @@
+//go:build go1.16
+// +build go1.16
+
+package echo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// AssetManifest maps static file names to fingerprinted names containing hash of the file content, e.g. `app.js` to
+// `app.3f9a1c2b.js`. Fingerprinted names change whenever file content changes so they can be cached by clients
+// forever.
+//
+// Manifest is built once when it is created. Files added or changed afterwards (i.e. with `os.DirFS`) are not
+// reflected until manifest is created again.
+//
+// AssetManifest implements fs.FS and opens files by both original and fingerprinted names.
+type AssetManifest struct {
+	filesystem fs.FS
+	// hashed maps original name to fingerprinted name
+	hashed map[string]string
+	// original maps fingerprinted name to original name
+	original map[string]string
+}
+
+const (
+	// assetHashLength is length of hex encoded content hash in fingerprinted name.
+	assetHashLength = 8
+	// assetCacheControl is Cache-Control header value for responses served by fingerprinted names.
+	assetCacheControl = "public, max-age=31536000, immutable"
+)
+
+// NewAssetManifest creates manifest of all files in the filesystem. Works the same with `embed.FS`, `os.DirFS` or any
+// other fs.FS implementation.
+//
+// When dealing with `embed.FS` use `fs := echo.MustSubFS(fs, "rootDirectory") to create sub fs which uses necessary
+// prefix for directory path.
+func NewAssetManifest(filesystem fs.FS) (*AssetManifest, error) {
+	m := &AssetManifest{
+		filesystem: filesystem,
+		hashed:     map[string]string{},
+		original:   map[string]string{},
+	}
+	err := fs.WalkDir(filesystem, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		hash, err := hashAsset(filesystem, name)
+		if err != nil {
+			return err
+		}
+		hashedName := fingerprintAssetName(name, hash)
+		m.hashed[name] = hashedName
+		m.original[hashedName] = name
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset manifest: %w", err)
+	}
+	return m, nil
+}
+
+// MustAssetManifest creates manifest of all files in the filesystem or panics on failure.
+// See: `NewAssetManifest()`.
+func MustAssetManifest(filesystem fs.FS) *AssetManifest {
+	m, err := NewAssetManifest(filesystem)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func hashAsset(filesystem fs.FS, name string) (string, error) {
+	f, err := filesystem.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:assetHashLength], nil
+}
+
+// fingerprintAssetName inserts hash before the file extension, e.g. `css/site.css` becomes `css/site.<hash>.css`.
+func fingerprintAssetName(name, hash string) string {
+	dir, file := path.Split(name)
+	ext := path.Ext(file)
+	if ext == file { // dot files like `.htaccess` have no base name
+		ext = ""
+	}
+	return dir + strings.TrimSuffix(file, ext) + "." + hash + ext
+}
+
+// Path returns fingerprinted name for the file name. Names not present in manifest are returned unchanged.
+func (m *AssetManifest) Path(name string) string {
+	if hashed, ok := m.Lookup(name); ok {
+		return hashed
+	}
+	return name
+}
+
+// Lookup returns fingerprinted name for the file name and true when the file is present in manifest.
+func (m *AssetManifest) Lookup(name string) (string, bool) {
+	hashed, ok := m.hashed[strings.TrimPrefix(name, "/")]
+	return hashed, ok
+}
+
+// URLFunc returns function returning URL of fingerprinted file under path prefix where manifest is served. It is
+// meant to be used in templates.
+//
+// Example:
+//
+//	assets := echo.MustAssetManifest(echo.MustSubFS(embeddedFiles, "assets"))
+//	e.StaticAssets("/assets", assets)
+//	funcs := template.FuncMap{"asset": assets.URLFunc("/assets")}
+//
+//	<script src="{{ asset "app.js" }}"></script> renders as <script src="/assets/app.3f9a1c2b.js"></script>
+func (m *AssetManifest) URLFunc(pathPrefix string) func(name string) string {
+	pathPrefix = strings.TrimSuffix(pathPrefix, "/") + "/"
+	return func(name string) string {
+		return pathPrefix + (&url.URL{Path: m.Path(name)}).EscapedPath()
+	}
+}
+
+// Open opens the named file by its original or fingerprinted name.
+func (m *AssetManifest) Open(name string) (fs.File, error) {
+	if original, ok := m.original[name]; ok {
+		name = original
+	}
+	return m.filesystem.Open(name)
+}
+
+// StaticAssets registers a new route with path prefix to serve files from the asset manifest.
+// See: `StaticAssetsHandler()`.
+func (e *Echo) StaticAssets(pathPrefix string, manifest *AssetManifest) *Route {
+	return e.Add(
+		http.MethodGet,
+		pathPrefix+"*",
+		StaticAssetsHandler(manifest),
+	)
+}
+
+// StaticAssets implements `Echo#StaticAssets()` for sub-routes within the Group.
+func (g *Group) StaticAssets(pathPrefix string, manifest *AssetManifest) *Route {
+	return g.Add(
+		http.MethodGet,
+		pathPrefix+"*",
+		StaticAssetsHandler(manifest),
+	)
+}
+
+// StaticAssetsHandler creates handler function to serve files from the asset manifest. Files requested by their
+// fingerprinted names are served with `Cache-Control: public, max-age=31536000, immutable` header. Files requested
+// by their original names are served as is.
+func StaticAssetsHandler(manifest *AssetManifest) HandlerFunc {
+	return func(c Context) error {
+		p, err := url.PathUnescape(c.Param("*"))
+		if err != nil {
+			return fmt.Errorf("failed to unescape path variable: %w", err)
+		}
+		name := path.Clean(strings.TrimPrefix(p, "/"))
+
+		original, ok := manifest.original[name]
+		if !ok {
+			if _, ok := manifest.hashed[name]; !ok {
+				return ErrNotFound
+			}
+			original = name
+		} else {
+			c.Response().Header().Set(HeaderCacheControl, assetCacheControl)
+		}
+		return fsFile(c, original, manifest.filesystem)
+	}
+}
@@ -0,0 +1,122 @@
+//go:build go1.16
+// +build go1.16
+
+package echo
+
+import (
+	"embed"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//go:embed _fixture/images
+var assetManifestTestFS embed.FS
+
+func TestAssetManifest(t *testing.T) {
+	m, err := NewAssetManifest(fstest.MapFS{
+		"app.js":           &fstest.MapFile{Data: []byte("console.log('app')")},
+		"css/site.min.css": &fstest.MapFile{Data: []byte("body{}")},
+		"LICENSE":          &fstest.MapFile{Data: []byte("MIT")},
+		".htaccess":        &fstest.MapFile{Data: []byte("deny")},
+	})
+	assert.NoError(t, err)
+
+	assert.Regexp(t, `^app\.[0-9a-f]{8}\.js$`, m.Path("app.js"))
+	assert.Regexp(t, `^css/site\.min\.[0-9a-f]{8}\.css$`, m.Path("css/site.min.css"))
+	assert.Regexp(t, `^LICENSE\.[0-9a-f]{8}$`, m.Path("LICENSE"))
+	assert.Regexp(t, `^\.htaccess\.[0-9a-f]{8}$`, m.Path(".htaccess"))
+	assert.Equal(t, m.Path("app.js"), m.Path("/app.js"))
+	assert.Equal(t, "missing.js", m.Path("missing.js"))
+
+	_, ok := m.Lookup("missing.js")
+	assert.False(t, ok)
+
+	asset := m.URLFunc("/assets/")
+	assert.Equal(t, "/assets/"+m.Path("app.js"), asset("app.js"))
+	assert.Equal(t, "/static/"+m.Path("app.js"), m.URLFunc("/static")("app.js"))
+
+	b, err := fs.ReadFile(m, m.Path("app.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, "console.log('app')", string(b))
+}
+
+func TestAssetManifest_sameForEmbedAndDirFS(t *testing.T) {
+	embedded := MustAssetManifest(MustSubFS(assetManifestTestFS, "_fixture/images"))
+	dir := MustAssetManifest(os.DirFS("_fixture/images"))
+
+	assert.Regexp(t, `^walle\.[0-9a-f]{8}\.png$`, embedded.Path("walle.png"))
+	assert.Equal(t, dir.Path("walle.png"), embedded.Path("walle.png"))
+}
+
+func TestNewAssetManifest_error(t *testing.T) {
+	_, err := NewAssetManifest(os.DirFS("_fixture/not-existing"))
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		MustAssetManifest(os.DirFS("_fixture/not-existing"))
+	})
+}
+
+func TestEcho_StaticAssets(t *testing.T) {
+	m := MustAssetManifest(fstest.MapFS{
+		"app.js": &fstest.MapFile{Data: []byte("console.log('app')")},
+	})
+
+	var testCases = []struct {
+		name               string
+		whenURL            string
+		expectStatus       int
+		expectCacheControl string
+	}{
+		{
+			name:               "ok, fingerprinted name is immutable",
+			whenURL:            "/assets/" + m.Path("app.js"),
+			expectStatus:       http.StatusOK,
+			expectCacheControl: "public, max-age=31536000, immutable",
+		},
+		{
+			name:         "ok, original name",
+			whenURL:      "/assets/app.js",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "nok, unknown fingerprint",
+			whenURL:      "/assets/app.00000000.js",
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:         "nok, outside of root",
+			whenURL:      "/assets/../app.js",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.StaticAssets("/assets/", m)
+			g := e.Group("/group")
+			g.StaticAssets("/assets/", m)
+
+			for _, prefix := range []string{"", "/group"} {
+				req := httptest.NewRequest(http.MethodGet, prefix+tc.whenURL, nil)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+
+				assert.Equal(t, tc.expectStatus, rec.Code)
+				assert.Equal(t, tc.expectCacheControl, rec.Header().Get(HeaderCacheControl))
+				if tc.expectStatus == http.StatusOK {
+					b, _ := ioutil.ReadAll(rec.Body)
+					assert.Equal(t, "console.log('app')", string(b))
+				}
+			}
+		})
+	}
+}
@@ -18,7 +18,29 @@ type (
 	}
 
 	// DefaultBinder is the default implementation of the Binder interface.
-	DefaultBinder struct{}
+	//
+	// Query params, form fields and path params can bind nested structs, maps and slices using bracket and dot
+	// notation, e.g. `filter[status]=open`, `items[0].sku=A1`, `ids[]=1&ids[]=2` or `a.b.c=1`.
+	//
+	// Struct tags can have options after the name, separated by commas:
+	//  * `default=<value>` - value used when the request does not have the field, i.e. `query:"page,default=1"`
+	//  * `required` - request must have the field with non-empty value, i.e. `header:"X-Tenant,required"`
+	//  * `explode` - slice values are split by delimiter (comma by default), i.e. `query:"ids,explode"` binds
+	//    `?ids=1,2&ids=3` to `[]int{1, 2, 3}`
+	//  * `delimiter=<value>` - splits slice values by given delimiter, i.e. `query:"ids,delimiter=|"`. `delimiter=,`
+	//    is a comma.
+	// Default value is exploded too, so for a slice with multiple default values use other delimiter than comma, i.e.
+	// `query:"ids,delimiter=|,default=1|2"`. Options apply to fields bound by flat keys (not nested keys).
+	// Missing required field, invalid default value and value which can not be converted to the field type are returned
+	// as *BindingError with Field and Source (the struct tag name) set.
+	DefaultBinder struct {
+		// MaxNestingDepth limits number of nested segments in a key (`a[b][c]` has 2).
+		// Optional. Default value DefaultBinderMaxNestingDepth.
+		MaxNestingDepth int
+		// MaxSliceIndex limits the largest index in a key (`items[100]`) so a client can not allocate huge slices.
+		// Optional. Default value DefaultBinderMaxSliceIndex.
+		MaxSliceIndex int
+	}
 
 	// BindUnmarshaler is the interface used to wrap the UnmarshalParam method.
 	// Types that don't implement this, but do implement encoding.TextUnmarshaler
@@ -38,7 +60,7 @@ func (b *DefaultBinder) BindPathParams(c Context, i interface{}) error {
 		params[name] = []string{values[i]}
 	}
 	if err := b.bindData(i, params, "param"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return newBindDataError(err)
 	}
 	return nil
 }
@@ -46,7 +68,7 @@ func (b *DefaultBinder) BindPathParams(c Context, i interface{}) error {
 // BindQueryParams binds query params to bindable object
 func (b *DefaultBinder) BindQueryParams(c Context, i interface{}) error {
 	if err := b.bindData(i, c.QueryParams(), "query"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return newBindDataError(err)
 	}
 	return nil
 }
@@ -88,7 +110,7 @@ func (b *DefaultBinder) BindBody(c Context, i interface{}) (err error) {
 			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
 		}
 		if err = b.bindData(i, params, "form"); err != nil {
-			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			return newBindDataError(err)
 		}
 	default:
 		return ErrUnsupportedMediaType
@@ -99,7 +121,7 @@ func (b *DefaultBinder) BindBody(c Context, i interface{}) (err error) {
 // BindHeaders binds HTTP headers to a bindable object
 func (b *DefaultBinder) BindHeaders(c Context, i interface{}) error {
 	if err := b.bindData(i, c.Request().Header, "header"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		return newBindDataError(err)
 	}
 	return nil
 }
@@ -125,11 +147,14 @@ func (b *DefaultBinder) Bind(i interface{}, c Context) (err error) {
 
 // bindData will bind data ONLY fields in destination struct that have EXPLICIT tag
 func (b *DefaultBinder) bindData(destination interface{}, data map[string][]string, tag string) error {
-	if destination == nil || len(data) == 0 {
+	if destination == nil {
 		return nil
 	}
 	typ := reflect.TypeOf(destination).Elem()
 	val := reflect.ValueOf(destination).Elem()
+	if len(data) == 0 && (typ.Kind() != reflect.Struct || !hasBindTagDefaults(typ, tag)) {
+		return nil
+	}
 
 	// Map
 	if typ.Kind() == reflect.Map {
@@ -148,6 +173,11 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 		return errors.New("binding element must be a struct")
 	}
 
+	var nested *nestedBinder
+	if (tag == "query" || tag == "form" || tag == "param") && hasNestedKeys(data) {
+		nested = b.nestedBinder(tag)
+	}
+
 	for i := 0; i < typ.NumField(); i++ {
 		typeField := typ.Field(i)
 		structField := val.Field(i)
@@ -160,7 +190,7 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 			continue
 		}
 		structFieldKind := structField.Kind()
-		inputFieldName := typeField.Tag.Get(tag)
+		inputFieldName, options := parseBindTag(typeField.Tag.Get(tag))
 		if typeField.Anonymous && structField.Kind() == reflect.Struct && inputFieldName != "" {
 			// if anonymous struct with query/param/form tags, report an error
 			return errors.New("query/param/form tags are not allowed with anonymous struct field")
@@ -193,34 +223,142 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 			}
 		}
 
-		if !exists {
+		if !exists && nested != nil {
+			node, err := nested.collect(inputFieldName, data)
+			if err != nil {
+				return newBindTagError(tag, inputFieldName, nil, err.Error(), err)
+			}
+			if node != nil {
+				if err := nested.bind(structField, node); err != nil {
+					return newBindTagError(tag, inputFieldName, nil, err.Error(), err)
+				}
+				continue
+			}
+		}
+
+		isDefault := false
+		if !exists && options.hasDefault {
+			inputValue, exists, isDefault = []string{options.defaultValue}, true, true
+		}
+		if options.required && (!exists || len(inputValue) == 0 || inputValue[0] == "") {
+			return newBindTagError(tag, inputFieldName, inputValue, "required field value is empty", nil)
+		}
+		if !exists || len(inputValue) == 0 {
 			continue
 		}
+		if err := bindFieldValues(typeField, structField, inputValue, options); err != nil {
+			if isDefault {
+				return newBindTagError(tag, inputFieldName, inputValue, "invalid default value", err)
+			}
+			return newBindTagError(tag, inputFieldName, inputValue, err.Error(), err)
+		}
+	}
+	return nil
+}
 
-		// Call this first, in case we're dealing with an alias to an array type
-		if ok, err := unmarshalField(typeField.Type.Kind(), inputValue[0], structField); ok {
-			if err != nil {
+// hasBindTagDefaults returns true when struct has field (or field of untagged struct field) with `required` or
+// `default` option in the tag, i.e. binding it fails or sets values even when there is no data.
+func hasBindTagDefaults(typ reflect.Type, tag string) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, options := parseBindTag(field.Tag.Get(tag))
+		if options.required || options.hasDefault {
+			return true
+		}
+		if name == "" && field.Type.Kind() == reflect.Struct && hasBindTagDefaults(field.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindFieldValues binds values to the struct field.
+func bindFieldValues(typeField reflect.StructField, structField reflect.Value, inputValue []string, options bindTagOptions) error {
+	// Call this first, in case we're dealing with an alias to an array type
+	if ok, err := unmarshalField(typeField.Type.Kind(), inputValue[0], structField); ok {
+		return err
+	}
+
+	structFieldKind := structField.Kind()
+	if options.explode && structFieldKind == reflect.Slice {
+		inputValue = explodeBindValues(inputValue, options.delimiter)
+	}
+	numElems := len(inputValue)
+	if structFieldKind == reflect.Slice && numElems > 0 {
+		sliceOf := structField.Type().Elem().Kind()
+		slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
+		for j := 0; j < numElems; j++ {
+			if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
 				return err
 			}
-			continue
 		}
+		structField.Set(slice)
+		return nil
+	}
+	return setWithProperType(typeField.Type.Kind(), inputValue[0], structField)
+}
 
-		numElems := len(inputValue)
-		if structFieldKind == reflect.Slice && numElems > 0 {
-			sliceOf := structField.Type().Elem().Kind()
-			slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
-			for j := 0; j < numElems; j++ {
-				if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
-					return err
-				}
-			}
-			val.Field(i).Set(slice)
-		} else if err := setWithProperType(typeField.Type.Kind(), inputValue[0], structField); err != nil {
-			return err
+// bindTagOptions are options of the struct tag used by DefaultBinder, see `DefaultBinder` for description.
+type bindTagOptions struct {
+	defaultValue string
+	hasDefault   bool
+	required     bool
+	explode      bool
+	delimiter    string
+}
 
+// parseBindTag splits struct tag value to the field name and options, i.e. `ids,explode,delimiter=|`.
+func parseBindTag(tag string) (string, bindTagOptions) {
+	parts := strings.Split(tag, ",")
+	options := bindTagOptions{delimiter: ","}
+	for i := 1; i < len(parts); i++ {
+		option := strings.TrimSpace(parts[i])
+		switch {
+		case option == "required":
+			options.required = true
+		case option == "explode":
+			options.explode = true
+		case strings.HasPrefix(option, "default="):
+			options.defaultValue, options.hasDefault = option[len("default="):], true
+		case strings.HasPrefix(option, "delimiter="):
+			options.explode = true
+			options.delimiter = option[len("delimiter="):]
+			if options.delimiter == "" && i+1 < len(parts) && parts[i+1] == "" {
+				// `delimiter=,` is split to `delimiter=` and empty part
+				options.delimiter = ","
+				i++
+			}
+			if options.delimiter == "" {
+				options.delimiter = ","
+			}
 		}
 	}
-	return nil
+	return parts[0], options
+}
+
+// explodeBindValues splits every value by delimiter.
+func explodeBindValues(values []string, delimiter string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, strings.Split(v, delimiter)...)
+	}
+	return result
+}
+
+// newBindDataError converts error returned by bindData to the error returned from binder methods. *BindingError is
+// returned as is, other errors as `400 Bad Request` *HTTPError.
+func newBindDataError(err error) error {
+	if be, ok := err.(*BindingError); ok {
+		return be
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+}
+
+// newBindTagError creates *BindingError for the field of the given source (struct tag name).
+func newBindTagError(source, field string, values []string, message string, internalError error) error {
+	err := NewBindingError(field, values, message, internalError).(*BindingError)
+	err.Source = source
+	return err
 }
 
 func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
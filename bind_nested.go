@@ -0,0 +1,316 @@
+package echo
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultBinderMaxNestingDepth is the default limit of nested key depth, see `DefaultBinder.MaxNestingDepth`.
+	DefaultBinderMaxNestingDepth = 8
+	// DefaultBinderMaxSliceIndex is the default limit of indexes in keys, see `DefaultBinder.MaxSliceIndex`.
+	DefaultBinderMaxSliceIndex = 100
+)
+
+// bindNode is a tree of values of nested keys. Keys `filter[status]`, `items[0].sku` and `a.b.c` are split into
+// segments (`filter`, `status`), (`items`, `0`, `sku`) and (`a`, `b`, `c`).
+type bindNode struct {
+	// path is the key (as sent by client) up to this node, used in errors
+	path     string
+	values   []string
+	children map[string]*bindNode
+}
+
+// nestedBinder binds nested keys of the tag (query, form or param) to struct fields, maps and slices.
+type nestedBinder struct {
+	tag      string
+	maxDepth int
+	maxIndex int
+}
+
+func (b *DefaultBinder) nestedBinder(tag string) *nestedBinder {
+	nb := &nestedBinder{tag: tag, maxDepth: b.MaxNestingDepth, maxIndex: b.MaxSliceIndex}
+	if nb.maxDepth <= 0 {
+		nb.maxDepth = DefaultBinderMaxNestingDepth
+	}
+	if nb.maxIndex <= 0 {
+		nb.maxIndex = DefaultBinderMaxSliceIndex
+	}
+	return nb
+}
+
+// hasNestedKeys returns true when any key uses bracket or dot notation.
+func hasNestedKeys(data map[string][]string) bool {
+	for k := range data {
+		if strings.ContainsAny(k, "[.") {
+			return true
+		}
+	}
+	return false
+}
+
+// collect returns tree of values of keys nested under name (`name[...]` or `name.` prefix, compared ignoring case) or
+// nil when there are no such keys. Keys with invalid notation are ignored.
+func (nb *nestedBinder) collect(name string, data map[string][]string) (*bindNode, error) {
+	var root *bindNode
+	for key, values := range data {
+		if len(key) <= len(name) || (key[len(name)] != '[' && key[len(name)] != '.') ||
+			!strings.EqualFold(key[:len(name)], name) {
+			continue
+		}
+		segments, ends, ok := splitBindKey(key[len(name):])
+		if !ok {
+			continue
+		}
+		if len(segments) > nb.maxDepth {
+			return nil, fmt.Errorf("%s: nesting depth exceeds limit of %d", key, nb.maxDepth)
+		}
+
+		if root == nil {
+			root = &bindNode{path: name}
+		}
+		n := root
+		for i, segment := range segments {
+			child, ok := n.children[segment]
+			if !ok {
+				child = &bindNode{path: key[:len(name)+ends[i]]}
+				if n.children == nil {
+					n.children = map[string]*bindNode{}
+				}
+				n.children[segment] = child
+			}
+			n = child
+		}
+		n.values = append(n.values, values...)
+	}
+	return root, nil
+}
+
+// splitBindKey splits rest of the key after field name into segments and returns end offsets of the segments in the
+// key. Returns false when notation is invalid, e.g. unclosed bracket or empty dot segment.
+func splitBindKey(key string) ([]string, []int, bool) {
+	var segments []string
+	var ends []int
+	for i := 0; i < len(key); {
+		switch key[i] {
+		case '[':
+			end := strings.IndexByte(key[i:], ']')
+			if end == -1 {
+				return nil, nil, false
+			}
+			segments = append(segments, key[i+1:i+end])
+			i += end + 1
+		case '.':
+			end := strings.IndexAny(key[i+1:], ".[")
+			if end == -1 {
+				end = len(key) - i - 1
+			}
+			if end == 0 {
+				return nil, nil, false
+			}
+			segments = append(segments, key[i+1:i+1+end])
+			i += end + 1
+		default:
+			return nil, nil, false
+		}
+		ends = append(ends, i)
+	}
+	return segments, ends, true
+}
+
+// sortedKeys returns keys of children sorted so binding (and errors) do not depend on map iteration order. Numeric
+// keys are sorted by their value.
+func (n *bindNode) sortedKeys() []string {
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, aErr := strconv.Atoi(keys[i])
+		b, bErr := strconv.Atoi(keys[j])
+		if aErr == nil && bErr == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// child returns child node by name compared ignoring case (exact match wins) same as flat keys are matched.
+func (n *bindNode) child(name string) *bindNode {
+	if c, ok := n.children[name]; ok {
+		return c
+	}
+	for k, c := range n.children {
+		if strings.EqualFold(k, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (nb *nestedBinder) bind(v reflect.Value, n *bindNode) error {
+	if len(n.children) == 0 {
+		return nb.bindLeaf(v, n)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return nb.bind(v.Elem(), n)
+	case reflect.Struct:
+		return nb.bindStruct(v, n)
+	case reflect.Map:
+		return nb.bindMap(v, n)
+	case reflect.Slice:
+		return nb.bindSlice(v, n)
+	}
+	// nested keys for value which can not have nested values are ignored same as unknown flat keys
+	return nil
+}
+
+func (nb *nestedBinder) bindLeaf(v reflect.Value, n *bindNode) error {
+	if len(n.values) == 0 {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if ok, err := unmarshalField(v.Kind(), n.values[0], v); ok {
+			return nb.wrap(n, err)
+		}
+		return nb.bindLeaf(v.Elem(), n)
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		// e.g. map[string]interface{}
+		v.Set(reflect.ValueOf(n.values[0]))
+		return nil
+	}
+	if v.Kind() == reflect.Slice {
+		if ok, err := unmarshalField(v.Kind(), n.values[0], v); ok {
+			return nb.wrap(n, err)
+		}
+		slice := reflect.MakeSlice(v.Type(), len(n.values), len(n.values))
+		for i, value := range n.values {
+			if err := setWithProperType(v.Type().Elem().Kind(), value, slice.Index(i)); err != nil {
+				return nb.wrap(n, err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	if v.Kind() == reflect.Struct || v.Kind() == reflect.Map {
+		if ok, err := unmarshalField(v.Kind(), n.values[0], v); ok {
+			return nb.wrap(n, err)
+		}
+		return nil // value for struct or map without nested key is ignored
+	}
+	return nb.wrap(n, setWithProperType(v.Kind(), n.values[0], v))
+}
+
+func (nb *nestedBinder) bindStruct(v reflect.Value, n *bindNode) error {
+	if isBindUnmarshaler(v) {
+		// struct is bound from single value (BindUnmarshaler, TextUnmarshaler) not from nested keys
+		return nil
+	}
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		name, _ := parseBindTag(typeField.Tag.Get(nb.tag))
+		if name == "" {
+			// same as flat binding, untagged struct fields are searched for tagged fields
+			if field.Kind() == reflect.Struct {
+				if err := nb.bindStruct(field, n); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if child := n.child(name); child != nil {
+			if err := nb.bind(field, child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (nb *nestedBinder) bindMap(v reflect.Value, n *bindNode) error {
+	typ := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(typ))
+	}
+	for _, k := range n.sortedKeys() {
+		child := n.children[k]
+		key := reflect.New(typ.Key()).Elem()
+		if err := setWithProperType(typ.Key().Kind(), k, key); err != nil {
+			return nb.wrap(child, err)
+		}
+		elem := reflect.New(typ.Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := nb.bind(elem, child); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+func (nb *nestedBinder) bindSlice(v reflect.Value, n *bindNode) error {
+	for _, k := range n.sortedKeys() {
+		child := n.children[k]
+		if k == "" {
+			// `ids[]=1&ids[]=2` appends values
+			for _, value := range child.values {
+				elem := reflect.New(v.Type().Elem()).Elem()
+				if err := nb.bind(elem, &bindNode{path: child.path, values: []string{value}}); err != nil {
+					return err
+				}
+				v.Set(reflect.Append(v, elem))
+			}
+			continue
+		}
+
+		index, err := strconv.Atoi(k)
+		if err != nil || index < 0 {
+			return fmt.Errorf("%s: invalid index %q", child.path, k)
+		}
+		if index > nb.maxIndex {
+			return fmt.Errorf("%s: index exceeds limit of %d", child.path, nb.maxIndex)
+		}
+		if index >= v.Len() {
+			grown := reflect.MakeSlice(v.Type(), index+1, index+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+		if err := nb.bind(v.Index(index), child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isBindUnmarshaler(v reflect.Value) bool {
+	switch v.Addr().Interface().(type) {
+	case BindUnmarshaler, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+func (nb *nestedBinder) wrap(n *bindNode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", n.path, err)
+}
@@ -0,0 +1,225 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nestedFilter struct {
+	Status string   `query:"status" form:"status"`
+	Tags   []string `query:"tags" form:"tags"`
+}
+
+type nestedItem struct {
+	SKU string `query:"sku" form:"sku"`
+	Qty int    `query:"qty" form:"qty"`
+}
+
+type nestedBindTarget struct {
+	Name   string                 `query:"name" form:"name"`
+	Filter nestedFilter           `query:"filter" form:"filter"`
+	Ptr    *nestedFilter          `query:"ptr" form:"ptr"`
+	Items  []nestedItem           `query:"items" form:"items"`
+	IDs    []int                  `query:"ids" form:"ids"`
+	Labels map[string]string      `query:"labels" form:"labels"`
+	Counts map[int]int            `query:"counts" form:"counts"`
+	Groups map[string][]int       `query:"groups" form:"groups"`
+	Extra  map[string]interface{} `query:"extra" form:"extra"`
+	A      struct {
+		B struct {
+			C int `query:"c" form:"c"`
+		} `query:"b" form:"b"`
+	} `query:"a" form:"a"`
+	Time *Timestamp `query:"time" form:"time"`
+}
+
+func TestDefaultBinder_bindNested(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenBinder *DefaultBinder
+		whenQuery   string
+		expect      func(t *testing.T, got nestedBindTarget)
+		expectError string
+	}{
+		{
+			name:      "ok, nested struct with brackets",
+			whenQuery: "filter[status]=open&filter[tags]=a&filter[tags]=b",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, nestedFilter{Status: "open", Tags: []string{"a", "b"}}, got.Filter)
+			},
+		},
+		{
+			name:      "ok, nested struct with dots and case insensitive keys",
+			whenQuery: "a.b.c=1&Filter.Status=open",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, 1, got.A.B.C)
+				assert.Equal(t, "open", got.Filter.Status)
+			},
+		},
+		{
+			name:      "ok, pointer to struct is allocated",
+			whenQuery: "ptr[status]=closed",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, &nestedFilter{Status: "closed"}, got.Ptr)
+			},
+		},
+		{
+			name:      "ok, indexed slice of structs",
+			whenQuery: "items[1].sku=B2&items[0].sku=A1&items[0][qty]=3",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, []nestedItem{{SKU: "A1", Qty: 3}, {SKU: "B2"}}, got.Items)
+			},
+		},
+		{
+			name:      "ok, slice with indexes and appended values",
+			whenQuery: "ids[]=5&ids[]=6",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, []int{5, 6}, got.IDs)
+			},
+		},
+		{
+			name:      "ok, maps",
+			whenQuery: "labels[env]=prod&labels.team=core&counts[1]=10&groups[x]=1&groups[x]=2&extra[k]=v",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, got.Labels)
+				assert.Equal(t, map[int]int{1: 10}, got.Counts)
+				assert.Equal(t, map[string][]int{"x": {1, 2}}, got.Groups)
+				assert.Equal(t, map[string]interface{}{"k": "v"}, got.Extra)
+			},
+		},
+		{
+			name:      "ok, flat key is bound as before",
+			whenQuery: "name=Jon&ids=1&ids=2&time=2016-12-06T19:09:05Z",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, "Jon", got.Name)
+				assert.Equal(t, []int{1, 2}, got.IDs)
+				assert.NotNil(t, got.Time)
+			},
+		},
+		{
+			name:      "ok, flat key wins over nested keys",
+			whenQuery: "ids=1&ids[0]=2",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, []int{1}, got.IDs)
+			},
+		},
+		{
+			name:      "ok, unknown and invalid keys are ignored",
+			whenQuery: "filter[unknown]=x&filter[status=open&filter..status=open&name[x]=y",
+			expect: func(t *testing.T, got nestedBindTarget) {
+				assert.Equal(t, nestedFilter{}, got.Filter)
+				assert.Equal(t, "", got.Name)
+			},
+		},
+		{
+			name:        "nok, conversion error reports full key",
+			whenQuery:   "items[2].qty=many",
+			expectError: `items[2].qty: strconv.ParseInt: parsing "many": invalid syntax`,
+		},
+		{
+			name:        "nok, map key conversion error",
+			whenQuery:   "counts[x]=1",
+			expectError: `counts[x]: strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+		{
+			name:        "nok, invalid index",
+			whenQuery:   "items[-1].sku=x",
+			expectError: `items[-1]: invalid index "-1"`,
+		},
+		{
+			name:        "nok, index over default limit",
+			whenQuery:   "items[101].sku=x",
+			expectError: "items[101]: index exceeds limit of 100",
+		},
+		{
+			name:        "nok, index over custom limit",
+			givenBinder: &DefaultBinder{MaxSliceIndex: 2},
+			whenQuery:   "ids[3]=1",
+			expectError: "ids[3]: index exceeds limit of 2",
+		},
+		{
+			name:        "nok, depth over custom limit",
+			givenBinder: &DefaultBinder{MaxNestingDepth: 1},
+			whenQuery:   "a.b.c=1",
+			expectError: "a.b.c: nesting depth exceeds limit of 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodGet, "/?"+tc.whenQuery, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			b := tc.givenBinder
+			if b == nil {
+				b = new(DefaultBinder)
+			}
+			got := nestedBindTarget{}
+			err := b.BindQueryParams(c, &got)
+
+			if tc.expectError != "" {
+				if assert.IsType(t, &BindingError{}, err) {
+					be := err.(*BindingError)
+					assert.Equal(t, http.StatusBadRequest, be.Code)
+					assert.Equal(t, tc.expectError, be.Message)
+					assert.Equal(t, "query", be.Source)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			tc.expect(t, got)
+		})
+	}
+}
+
+func TestDefaultBinder_bindNestedForm(t *testing.T) {
+	form := url.Values{}
+	form.Add("filter[status]", "open")
+	form.Add("items[0][sku]", "A1")
+	form.Add("items[0][qty]", "2")
+	form.Add("labels[env]", "prod")
+
+	e := New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	got := nestedBindTarget{}
+	err := c.Bind(&got)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "open", got.Filter.Status)
+	assert.Equal(t, []nestedItem{{SKU: "A1", Qty: 2}}, got.Items)
+	assert.Equal(t, map[string]string{"env": "prod"}, got.Labels)
+}
+
+func TestSplitBindKey(t *testing.T) {
+	var testCases = []struct {
+		whenKey        string
+		expectSegments []string
+		expectOK       bool
+	}{
+		{whenKey: "[a]", expectSegments: []string{"a"}, expectOK: true},
+		{whenKey: "[a][0].b", expectSegments: []string{"a", "0", "b"}, expectOK: true},
+		{whenKey: ".a.b[]", expectSegments: []string{"a", "b", ""}, expectOK: true},
+		{whenKey: "[a", expectOK: false},
+		{whenKey: "..a", expectOK: false},
+		{whenKey: "[a]b", expectOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.whenKey, func(t *testing.T) {
+			segments, _, ok := splitBindKey(tc.whenKey)
+			assert.Equal(t, tc.expectOK, ok)
+			assert.Equal(t, tc.expectSegments, segments)
+		})
+	}
+}
@@ -294,9 +294,11 @@ func TestBindHeaderParamBadType(t *testing.T) {
 	err := (&DefaultBinder{}).BindHeaders(c, u)
 	assert.Error(t, err)
 
-	httpErr, ok := err.(*HTTPError)
+	bindErr, ok := err.(*BindingError)
 	if assert.True(t, ok) {
-		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		assert.Equal(t, http.StatusBadRequest, bindErr.Code)
+		assert.Equal(t, "id", bindErr.Field)
+		assert.Equal(t, "header", bindErr.Source)
 	}
 }
 
@@ -777,7 +779,7 @@ func TestDefaultBinder_BindToStructFromMixedSources(t *testing.T) {
 			givenURL:     "/api/real_node/endpoint?id=nope",
 			givenContent: strings.NewReader(`{"id": 1, "node": "zzz"}`),
 			expect:       &Opts{ID: 0, Node: "node_from_path"}, // path params binding has already modified bind target
-			expectError:  "code=400, message=strconv.ParseInt: parsing \"nope\": invalid syntax, internal=strconv.ParseInt: parsing \"nope\": invalid syntax",
+			expectError:  "code=400, message=strconv.ParseInt: parsing \"nope\": invalid syntax, internal=strconv.ParseInt: parsing \"nope\": invalid syntax, field=id, source=query",
 		},
 		{
 			name:         "nok, GET body bind failure - trying to bind json array to struct",
@@ -1043,3 +1045,193 @@ func TestDefaultBinder_BindBody(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultBinder_tagOptions(t *testing.T) {
+	type Opts struct {
+		Page   int      `query:"page,default=1"`
+		Sort   string   `query:"sort,default=name"`
+		Tenant string   `query:"tenant,required"`
+		IDs    []int    `query:"ids,explode"`
+		Tags   []string `query:"tags,delimiter=|,default=a|b"`
+		Names  []string `query:"names,delimiter=,"`
+	}
+
+	var testCases = []struct {
+		name         string
+		whenURL      string
+		expect       Opts
+		expectError  string
+		expectField  string
+		expectSource string
+	}{
+		{
+			name:    "ok, defaults are used for missing values",
+			whenURL: "/?tenant=acme",
+			expect:  Opts{Page: 1, Sort: "name", Tenant: "acme", Tags: []string{"a", "b"}},
+		},
+		{
+			name:    "ok, request values win over defaults",
+			whenURL: "/?tenant=acme&page=3&sort=&tags=x",
+			expect:  Opts{Page: 3, Sort: "", Tenant: "acme", Tags: []string{"x"}},
+		},
+		{
+			name:    "ok, values are exploded",
+			whenURL: "/?tenant=acme&ids=1,2&ids=3&tags=x|y&names=a,b",
+			expect: Opts{
+				Page:   1,
+				Sort:   "name",
+				Tenant: "acme",
+				IDs:    []int{1, 2, 3},
+				Tags:   []string{"x", "y"},
+				Names:  []string{"a", "b"},
+			},
+		},
+		{
+			name:         "nok, required value is missing",
+			whenURL:      "/?page=2",
+			expectError:  "code=400, message=required field value is empty, field=tenant, source=query",
+			expectField:  "tenant",
+			expectSource: "query",
+		},
+		{
+			name:         "nok, required value is empty",
+			whenURL:      "/?tenant=",
+			expectError:  "code=400, message=required field value is empty, field=tenant, source=query",
+			expectField:  "tenant",
+			expectSource: "query",
+		},
+		{
+			name:        "nok, exploded value is invalid",
+			whenURL:     "/?tenant=acme&ids=1,x",
+			expectError: "code=400, message=strconv.ParseInt: parsing \"x\": invalid syntax, internal=strconv.ParseInt: parsing \"x\": invalid syntax, field=ids, source=query",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			result := Opts{}
+			err := new(DefaultBinder).BindQueryParams(c, &result)
+
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				if tc.expectField != "" {
+					be, ok := err.(*BindingError)
+					if assert.True(t, ok) {
+						assert.Equal(t, tc.expectField, be.Field)
+						assert.Equal(t, tc.expectSource, be.Source)
+					}
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+		})
+	}
+}
+
+func TestDefaultBinder_tagOptionsSources(t *testing.T) {
+	type Opts struct {
+		Tenant string `header:"X-Tenant,required"`
+		ID     int    `param:"id,required"`
+		Limit  int    `header:"X-Limit,default=x"`
+	}
+
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	b := new(DefaultBinder)
+
+	err := b.BindPathParams(c, &Opts{})
+	assert.EqualError(t, err, "code=400, message=required field value is empty, field=id, source=param")
+
+	err = b.BindHeaders(c, &Opts{})
+	assert.EqualError(t, err, "code=400, message=required field value is empty, field=X-Tenant, source=header")
+
+	req.Header.Set("X-Tenant", "acme")
+	err = b.BindHeaders(c, &Opts{})
+	assert.EqualError(t, err, "code=400, message=invalid default value, internal=strconv.ParseInt: parsing \"x\": invalid syntax, field=X-Limit, source=header")
+}
+
+func TestDefaultBinder_conversionError(t *testing.T) {
+	type Opts struct {
+		Page int `query:"page"`
+	}
+
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/?page=abc", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err := new(DefaultBinder).BindQueryParams(c, &Opts{})
+
+	be, ok := err.(*BindingError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusBadRequest, be.Code)
+		assert.Equal(t, "page", be.Field)
+		assert.Equal(t, "query", be.Source)
+		assert.Equal(t, []string{"abc"}, be.Values)
+	}
+}
+
+func TestDefaultBinder_emptyData(t *testing.T) {
+	type Embedded struct {
+		Name string `query:"name"`
+	}
+	type Opts struct {
+		Embedded `query:"embedded"`
+	}
+	type Defaults struct {
+		Nested struct {
+			Page int `query:"page,default=1"`
+		}
+	}
+
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	b := new(DefaultBinder)
+
+	// struct is not walked when client sent nothing and there are no tag options to apply
+	assert.NoError(t, b.BindQueryParams(c, &Opts{}))
+
+	defaults := Defaults{}
+	assert.NoError(t, b.BindQueryParams(c, &defaults))
+	assert.Equal(t, 1, defaults.Nested.Page)
+}
+
+func TestParseBindTag(t *testing.T) {
+	var testCases = []struct {
+		whenTag       string
+		expectName    string
+		expectOptions bindTagOptions
+	}{
+		{whenTag: "id", expectName: "id", expectOptions: bindTagOptions{delimiter: ","}},
+		{whenTag: "", expectName: "", expectOptions: bindTagOptions{delimiter: ","}},
+		{
+			whenTag:       "page, default=1, required",
+			expectName:    "page",
+			expectOptions: bindTagOptions{defaultValue: "1", hasDefault: true, required: true, delimiter: ","},
+		},
+		{
+			whenTag:       "ids,delimiter=,,default=",
+			expectName:    "ids",
+			expectOptions: bindTagOptions{hasDefault: true, explode: true, delimiter: ","},
+		},
+		{
+			whenTag:       "ids,explode,delimiter=;,unknown",
+			expectName:    "ids",
+			expectOptions: bindTagOptions{explode: true, delimiter: ";"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.whenTag, func(t *testing.T) {
+			name, options := parseBindTag(tc.whenTag)
+			assert.Equal(t, tc.expectName, name)
+			assert.Equal(t, tc.expectOptions, options)
+		})
+	}
+}
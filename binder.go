@@ -1,8 +1,11 @@
 package echo
 
 import (
+	"encoding"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +16,8 @@ import (
     * QueryParamsBinder(c) - binds query parameters (source URL)
     * PathParamsBinder(c) - binds path parameters (source URL)
     * FormFieldBinder(c) - binds form fields (source URL + body)
+    * HeaderBinder(c) - binds request headers
+    * CookieBinder(c) - binds request cookies
 
 	Example:
   ```go
@@ -52,6 +57,11 @@ import (
 		* time
 		* duration
 		* BindUnmarshaler() interface
+		* TextUnmarshaler() - encoding.TextUnmarshaler interface
+		* TextUnmarshalers() - slices of encoding.TextUnmarshaler types (generic function, Go 1.18+)
+		* IP() - net.IP
+		* URL() - url.URL
+		* Enum() - string restricted to set of allowed values
 		* UnixTime() - converts unix time (integer) to time.Time
 		* UnixTimeNano() - converts unix time with nano second precision (integer) to time.Time
 		* CustomFunc() - callback function for your custom conversion logic. Signature `func(values []string) []error`
@@ -61,6 +71,8 @@ import (
 type BindingError struct {
 	// Field is the field name where value binding failed
 	Field string `json:"field"`
+	// Source is the source of the field (`query`, `param`, `form` or `header`) when error is returned by DefaultBinder.
+	Source string `json:"source,omitempty"`
 	// Values of parameter that failed to bind.
 	Values []string `json:"-"`
 	*HTTPError
@@ -81,6 +93,9 @@ func NewBindingError(sourceParam string, values []string, message interface{}, i
 
 // Error returns error message
 func (be *BindingError) Error() string {
+	if be.Source != "" {
+		return fmt.Sprintf("%s, field=%s, source=%s", be.HTTPError.Error(), be.Field, be.Source)
+	}
 	return fmt.Sprintf("%s, field=%s", be.HTTPError.Error(), be.Field)
 }
 
@@ -164,6 +179,45 @@ func FormFieldBinder(c Context) *ValueBinder {
 	return vb
 }
 
+// HeaderBinder creates request header value binder. Header names are case-insensitive.
+func HeaderBinder(c Context) *ValueBinder {
+	return &ValueBinder{
+		failFast: true,
+		ValueFunc: func(sourceParam string) string {
+			return c.Request().Header.Get(sourceParam)
+		},
+		ValuesFunc: func(sourceParam string) []string {
+			return c.Request().Header.Values(sourceParam)
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
+// CookieBinder creates request cookie value binder. When request has multiple cookies with the same name, ValueFunc
+// returns the first one.
+func CookieBinder(c Context) *ValueBinder {
+	return &ValueBinder{
+		failFast: true,
+		ValueFunc: func(sourceParam string) string {
+			cookie, err := c.Cookie(sourceParam)
+			if err != nil {
+				return ""
+			}
+			return cookie.Value
+		},
+		ValuesFunc: func(sourceParam string) []string {
+			var values []string
+			for _, cookie := range c.Cookies() {
+				if cookie.Name == sourceParam {
+					values = append(values, cookie.Value)
+				}
+			}
+			return values
+		},
+		ErrorFunc: NewBindingError,
+	}
+}
+
 // FailFast set internal flag to indicate if binding methods will return early (without binding) when previous bind failed
 // NB: call this method before any other binding methods as it modifies binding methods behaviour
 func (b *ValueBinder) FailFast(value bool) *ValueBinder {
@@ -1228,3 +1282,168 @@ func (b *ValueBinder) unixTime(sourceParam string, dest *time.Time, valueMustExi
 	}
 	return b
 }
+
+// TextUnmarshaler binds parameter to destination implementing encoding.TextUnmarshaler interface
+func (b *ValueBinder) TextUnmarshaler(sourceParam string, dest encoding.TextUnmarshaler) *ValueBinder {
+	return b.textUnmarshaler(sourceParam, dest, false)
+}
+
+// MustTextUnmarshaler requires parameter value to exist to be bind to destination implementing encoding.TextUnmarshaler
+// interface. Returns error when value does not exist
+func (b *ValueBinder) MustTextUnmarshaler(sourceParam string, dest encoding.TextUnmarshaler) *ValueBinder {
+	return b.textUnmarshaler(sourceParam, dest, true)
+}
+
+func (b *ValueBinder) textUnmarshaler(sourceParam string, dest encoding.TextUnmarshaler, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		}
+		return b
+	}
+	if err := dest.UnmarshalText([]byte(value)); err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to TextUnmarshaler interface", err))
+	}
+	return b
+}
+
+// IP binds parameter to net.IP variable
+func (b *ValueBinder) IP(sourceParam string, dest *net.IP) *ValueBinder {
+	return b.ip(sourceParam, dest, false)
+}
+
+// MustIP requires parameter value to exist to be bind to net.IP variable. Returns error when value does not exist
+func (b *ValueBinder) MustIP(sourceParam string, dest *net.IP) *ValueBinder {
+	return b.ip(sourceParam, dest, true)
+}
+
+func (b *ValueBinder) ip(sourceParam string, dest *net.IP, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		}
+		return b
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to IP", nil))
+		return b
+	}
+	*dest = ip
+	return b
+}
+
+// IPs binds parameter values to slice of net.IP variables
+func (b *ValueBinder) IPs(sourceParam string, dest *[]net.IP) *ValueBinder {
+	return b.ips(sourceParam, dest, false)
+}
+
+// MustIPs requires parameter values to exist to be bind to slice of net.IP variables. Returns error when values does not exist
+func (b *ValueBinder) MustIPs(sourceParam string, dest *[]net.IP) *ValueBinder {
+	return b.ips(sourceParam, dest, true)
+}
+
+func (b *ValueBinder) ips(sourceParam string, dest *[]net.IP, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	values := b.ValuesFunc(sourceParam)
+	if len(values) == 0 {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{}, "required field value is empty", nil))
+		}
+		return b
+	}
+
+	tmp := make([]net.IP, len(values))
+	for i, v := range values {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			b.setError(b.ErrorFunc(sourceParam, []string{v}, "failed to bind field value to IP", nil))
+			if b.failFast {
+				return b
+			}
+			continue
+		}
+		tmp[i] = ip
+	}
+	if b.errors == nil {
+		*dest = tmp
+	}
+	return b
+}
+
+// URL binds parameter to url.URL variable
+func (b *ValueBinder) URL(sourceParam string, dest *url.URL) *ValueBinder {
+	return b.url(sourceParam, dest, false)
+}
+
+// MustURL requires parameter value to exist to be bind to url.URL variable. Returns error when value does not exist
+func (b *ValueBinder) MustURL(sourceParam string, dest *url.URL) *ValueBinder {
+	return b.url(sourceParam, dest, true)
+}
+
+func (b *ValueBinder) url(sourceParam string, dest *url.URL, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		}
+		return b
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		b.setError(b.ErrorFunc(sourceParam, []string{value}, "failed to bind field value to URL", err))
+		return b
+	}
+	*dest = *u
+	return b
+}
+
+// Enum binds parameter to string variable when value is one of the allowed values. Returns error for other values
+func (b *ValueBinder) Enum(sourceParam string, dest *string, allowed ...string) *ValueBinder {
+	return b.enum(sourceParam, dest, allowed, false)
+}
+
+// MustEnum requires parameter value to exist to be bind to string variable when value is one of the allowed values.
+// Returns error when value does not exist or is not allowed
+func (b *ValueBinder) MustEnum(sourceParam string, dest *string, allowed ...string) *ValueBinder {
+	return b.enum(sourceParam, dest, allowed, true)
+}
+
+func (b *ValueBinder) enum(sourceParam string, dest *string, allowed []string, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	value := b.ValueFunc(sourceParam)
+	if value == "" {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{value}, "required field value is empty", nil))
+		}
+		return b
+	}
+	for _, a := range allowed {
+		if value == a {
+			*dest = value
+			return b
+		}
+	}
+	b.setError(b.ErrorFunc(sourceParam, []string{value}, "field value must be one of: "+strings.Join(allowed, ", "), nil))
+	return b
+}
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+package echo
+
+import "encoding"
+
+// TextUnmarshalers binds parameter values to slice of T where *T implements encoding.TextUnmarshaler interface.
+// Methods can not have type parameters so this is a function taking the binder, i.e.
+//
+//	b := echo.QueryParamsBinder(c)
+//	var ids []uuid.UUID
+//	err := echo.TextUnmarshalers(b, "id", &ids).BindError()
+func TextUnmarshalers[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](b *ValueBinder, sourceParam string, dest *[]T) *ValueBinder {
+	return textUnmarshalers[T, PT](b, sourceParam, dest, false)
+}
+
+// MustTextUnmarshalers requires parameter values to exist to be bind to slice of T where *T implements
+// encoding.TextUnmarshaler interface. Returns error when values does not exist
+func MustTextUnmarshalers[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](b *ValueBinder, sourceParam string, dest *[]T) *ValueBinder {
+	return textUnmarshalers[T, PT](b, sourceParam, dest, true)
+}
+
+func textUnmarshalers[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](b *ValueBinder, sourceParam string, dest *[]T, valueMustExist bool) *ValueBinder {
+	if b.failFast && b.errors != nil {
+		return b
+	}
+
+	values := b.ValuesFunc(sourceParam)
+	if len(values) == 0 {
+		if valueMustExist {
+			b.setError(b.ErrorFunc(sourceParam, []string{}, "required field value is empty", nil))
+		}
+		return b
+	}
+
+	tmp := make([]T, len(values))
+	for i, v := range values {
+		if err := PT(&tmp[i]).UnmarshalText([]byte(v)); err != nil {
+			b.setError(b.ErrorFunc(sourceParam, []string{v}, "failed to bind field value to TextUnmarshaler interface", err))
+			if b.failFast {
+				return b
+			}
+		}
+	}
+	if b.errors == nil {
+		*dest = tmp
+	}
+	return b
+}
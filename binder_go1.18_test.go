@@ -0,0 +1,92 @@
+//go:build go1.18
+// +build go1.18
+
+package echo
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextUnmarshalers(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		givenFailFast bool
+		whenURL       string
+		whenMust      bool
+		expectValue   []net.IP
+		expectError   string
+	}{
+		{
+			name:        "ok, binds value",
+			whenURL:     "/search?param=127.0.0.1&param=::1",
+			expectValue: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		},
+		{
+			name:    "ok, params values empty, value is not changed",
+			whenURL: "/search?nope=1",
+		},
+		{
+			name:          "nok, previous errors fail fast without binding value",
+			givenFailFast: true,
+			whenURL:       "/search?param=127.0.0.1",
+			expectError:   "previous error",
+		},
+		{
+			name:        "nok, conversion fails, value is not changed",
+			whenURL:     "/search?param=127.0.0.1&param=nope",
+			expectError: "code=400, message=failed to bind field value to TextUnmarshaler interface, internal=invalid IP address: nope, field=param",
+		},
+		{
+			name:        "ok (must), binds value",
+			whenMust:    true,
+			whenURL:     "/search?param=127.0.0.1",
+			expectValue: []net.IP{net.ParseIP("127.0.0.1")},
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c).FailFast(tc.givenFailFast)
+			if tc.givenFailFast {
+				b.errors = []error{errors.New("previous error")}
+			}
+
+			var dest []net.IP
+			var err error
+			if tc.whenMust {
+				err = MustTextUnmarshalers(b, "param", &dest).BindError()
+			} else {
+				err = TextUnmarshalers(b, "param", &dest).BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTextUnmarshalers_notFailFast(t *testing.T) {
+	c := createTestContext("/search?param=nope&param=127.0.0.1&param=nope2", nil, nil)
+	b := QueryParamsBinder(c).FailFast(false)
+
+	var dest []net.IP
+	errs := TextUnmarshalers(b, "param", &dest).BindErrors()
+
+	assert.Len(t, errs, 2)
+	assert.Nil(t, dest)
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
@@ -2755,3 +2757,362 @@ func BenchmarkValueBinder_BindInt64_10_fields(b *testing.B) {
 		}
 	}
 }
+
+func TestHeaderBinder(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Add("X-Id", "1")
+	req.Header.Add("X-Id", "2")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var tenant string
+	var ids []int64
+	var missing []int64
+	err := HeaderBinder(c).
+		String("x-tenant", &tenant).
+		Int64s("X-Id", &ids).
+		Int64s("X-Missing", &missing).
+		BindError()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.Nil(t, missing)
+
+	err = HeaderBinder(c).MustString("X-Missing", &tenant).BindError()
+	assert.EqualError(t, err, "code=400, message=required field value is empty, field=X-Missing")
+}
+
+func TestCookieBinder(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	req.AddCookie(&http.Cookie{Name: "page", Value: "2"})
+	req.AddCookie(&http.Cookie{Name: "page", Value: "3"})
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var session string
+	var page int
+	var pages []int
+	err := CookieBinder(c).
+		String("session", &session).
+		Int("page", &page).
+		Ints("page", &pages).
+		BindError()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", session)
+	assert.Equal(t, 2, page)
+	assert.Equal(t, []int{2, 3}, pages)
+
+	err = CookieBinder(c).MustInts("missing", &pages).BindError()
+	assert.EqualError(t, err, "code=400, message=required field value is empty, field=missing")
+}
+
+func TestValueBinder_IP(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		givenFailFast bool
+		whenURL       string
+		whenMust      bool
+		expectValue   net.IP
+		expectError   string
+	}{
+		{
+			name:        "ok, binds value",
+			whenURL:     "/search?param=192.168.0.1&param=::1",
+			expectValue: net.ParseIP("192.168.0.1"),
+		},
+		{
+			name:        "ok, binds IPv6 value",
+			whenURL:     "/search?param=2001:db8::1",
+			expectValue: net.ParseIP("2001:db8::1"),
+		},
+		{
+			name:    "ok, params values empty, value is not changed",
+			whenURL: "/search?nope=1",
+		},
+		{
+			name:          "nok, previous errors fail fast without binding value",
+			givenFailFast: true,
+			whenURL:       "/search?param=127.0.0.1",
+			expectError:   "previous error",
+		},
+		{
+			name:        "nok, conversion fails, value is not changed",
+			whenURL:     "/search?param=300.0.0.1",
+			expectError: "code=400, message=failed to bind field value to IP, field=param",
+		},
+		{
+			name:        "ok (must), binds value",
+			whenMust:    true,
+			whenURL:     "/search?param=10.0.0.1",
+			expectValue: net.ParseIP("10.0.0.1"),
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c).FailFast(tc.givenFailFast)
+			if tc.givenFailFast {
+				b.errors = []error{errors.New("previous error")}
+			}
+
+			var dest net.IP
+			var err error
+			if tc.whenMust {
+				err = b.MustIP("param", &dest).BindError()
+			} else {
+				err = b.IP("param", &dest).BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValueBinder_IPs(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		givenFailFast bool
+		whenURL       string
+		whenMust      bool
+		expectValue   []net.IP
+		expectError   string
+	}{
+		{
+			name:        "ok, binds value",
+			whenURL:     "/search?param=192.168.0.1&param=::1",
+			expectValue: []net.IP{net.ParseIP("192.168.0.1"), net.ParseIP("::1")},
+		},
+		{
+			name:    "ok, params values empty, value is not changed",
+			whenURL: "/search?nope=1",
+		},
+		{
+			name:        "nok, conversion fails, value is not changed",
+			whenURL:     "/search?param=127.0.0.1&param=nope",
+			expectError: "code=400, message=failed to bind field value to IP, field=param",
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c).FailFast(tc.givenFailFast)
+
+			var dest []net.IP
+			var err error
+			if tc.whenMust {
+				err = b.MustIPs("param", &dest).BindError()
+			} else {
+				err = b.IPs("param", &dest).BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValueBinder_URL(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenURL     string
+		whenMust    bool
+		expectValue string
+		expectError string
+	}{
+		{
+			name:        "ok, binds value",
+			whenURL:     "/search?param=" + url.QueryEscape("https://example.com/a?b=c"),
+			expectValue: "https://example.com/a?b=c",
+		},
+		{
+			name:    "ok, params values empty, value is not changed",
+			whenURL: "/search?nope=1",
+		},
+		{
+			name:        "nok, conversion fails, value is not changed",
+			whenURL:     "/search?param=" + url.QueryEscape("http://[::1"),
+			expectError: "code=400, message=failed to bind field value to URL, internal=parse \"http://[::1\": missing ']' in host, field=param",
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c)
+
+			var dest url.URL
+			var err error
+			if tc.whenMust {
+				err = b.MustURL("param", &dest).BindError()
+			} else {
+				err = b.URL("param", &dest).BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest.String())
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValueBinder_Enum(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		givenFailFast bool
+		whenURL       string
+		whenMust      bool
+		expectValue   string
+		expectError   string
+	}{
+		{
+			name:        "ok, binds allowed value",
+			whenURL:     "/search?param=desc",
+			expectValue: "desc",
+		},
+		{
+			name:        "ok, params values empty, value is not changed",
+			whenURL:     "/search?nope=1",
+			expectValue: "default",
+		},
+		{
+			name:          "nok, previous errors fail fast without binding value",
+			givenFailFast: true,
+			whenURL:       "/search?param=asc",
+			expectValue:   "default",
+			expectError:   "previous error",
+		},
+		{
+			name:        "nok, value is not allowed, value is not changed",
+			whenURL:     "/search?param=DESC",
+			expectValue: "default",
+			expectError: "code=400, message=field value must be one of: asc, desc, field=param",
+		},
+		{
+			name:        "ok (must), binds allowed value",
+			whenMust:    true,
+			whenURL:     "/search?param=asc",
+			expectValue: "asc",
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectValue: "default",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c).FailFast(tc.givenFailFast)
+			if tc.givenFailFast {
+				b.errors = []error{errors.New("previous error")}
+			}
+
+			dest := "default"
+			var err error
+			if tc.whenMust {
+				err = b.MustEnum("param", &dest, "asc", "desc").BindError()
+			} else {
+				err = b.Enum("param", &dest, "asc", "desc").BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValueBinder_TextUnmarshaler(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenURL     string
+		whenMust    bool
+		expectValue net.IP
+		expectError string
+	}{
+		{
+			name:        "ok, binds value",
+			whenURL:     "/search?param=127.0.0.1",
+			expectValue: net.ParseIP("127.0.0.1"),
+		},
+		{
+			name:    "ok, params values empty, value is not changed",
+			whenURL: "/search?nope=1",
+		},
+		{
+			name:        "nok, conversion fails, value is not changed",
+			whenURL:     "/search?param=nope",
+			expectError: "code=400, message=failed to bind field value to TextUnmarshaler interface, internal=invalid IP address: nope, field=param",
+		},
+		{
+			name:        "nok (must), params values empty, returns error, value is not changed",
+			whenMust:    true,
+			whenURL:     "/search?nope=1",
+			expectError: "code=400, message=required field value is empty, field=param",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := createTestContext(tc.whenURL, nil, nil)
+			b := QueryParamsBinder(c)
+
+			var dest net.IP
+			var err error
+			if tc.whenMust {
+				err = b.MustTextUnmarshaler("param", &dest).BindError()
+			} else {
+				err = b.TextUnmarshaler("param", &dest).BindError()
+			}
+
+			assert.Equal(t, tc.expectValue, dest)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 )
 
 type (
@@ -35,6 +36,12 @@ type (
 		// IsWebSocket returns true if HTTP connection is WebSocket otherwise false.
 		IsWebSocket() bool
 
+		// WebSocket upgrades the connection to WebSocket protocol with `DefaultWebSocketConfig`.
+		WebSocket() (*WebSocketConn, error)
+
+		// WebSocketWithConfig upgrades the connection to WebSocket protocol with config.
+		WebSocketWithConfig(config WebSocketConfig) (*WebSocketConn, error)
+
 		// Scheme returns the HTTP protocol scheme, `http` or `https`.
 		Scheme() string
 
@@ -64,6 +71,16 @@ type (
 		// SetParamValues sets path parameter values.
 		SetParamValues(values ...string)
 
+		// HostParam returns the host label captured by `{name}` (or `*` for wildcard) in the host pattern of the
+		// matched virtual host. See `Echo#Host`.
+		HostParam(name string) string
+
+		// HostParamNames returns host parameter names.
+		HostParamNames() []string
+
+		// HostParamValues returns host parameter values.
+		HostParamValues() []string
+
 		// QueryParam returns the query param for the provided name.
 		QueryParam(name string) string
 
@@ -85,6 +102,10 @@ type (
 		// MultipartForm returns the multipart form.
 		MultipartForm() (*multipart.Form, error)
 
+		// StreamMultipart reads multipart form from the request body one part at a time passing file parts to
+		// `MultipartConfig.Sink`. Contrary to `MultipartForm` files are never buffered as a whole by Echo.
+		StreamMultipart(config MultipartConfig) (*MultipartUpload, error)
+
 		// Cookie returns the named cookie provided in the request.
 		Cookie(name string) (*http.Cookie, error)
 
@@ -94,6 +115,22 @@ type (
 		// Cookies returns the HTTP cookies sent with the request.
 		Cookies() []*http.Cookie
 
+		// SignedCookie returns the named cookie provided in the request with its value verified against keys of
+		// `Echo#CookieKeyRing`.
+		SignedCookie(name string) (*http.Cookie, error)
+
+		// SetSignedCookie signs value of the cookie with the newest key of `Echo#CookieKeyRing` and adds a
+		// `Set-Cookie` header in HTTP response.
+		SetSignedCookie(cookie *http.Cookie) error
+
+		// EncryptedCookie returns the named cookie provided in the request with its value decrypted using keys of
+		// `Echo#CookieKeyRing`.
+		EncryptedCookie(name string) (*http.Cookie, error)
+
+		// SetEncryptedCookie encrypts value of the cookie with the newest key of `Echo#CookieKeyRing` and adds a
+		// `Set-Cookie` header in HTTP response.
+		SetEncryptedCookie(cookie *http.Cookie) error
+
 		// Get retrieves data from the context.
 		Get(key string) interface{}
 
@@ -153,6 +190,17 @@ type (
 		// Stream sends a streaming response with status code and content type.
 		Stream(code int, contentType string, r io.Reader) error
 
+		// BlobRange sends a blob response with content type honoring `Range` and `If-Range` request headers.
+		BlobRange(contentType string, b []byte) error
+
+		// StreamRange sends the content of seekable reader with content type honoring `Range` and `If-Range`
+		// request headers.
+		StreamRange(contentType string, content io.ReadSeeker) error
+
+		// StreamRangeAt sends size bytes of the content with content type honoring `Range` and `If-Range` request
+		// headers.
+		StreamRangeAt(contentType string, content io.ReaderAt, size int64) error
+
 		// File sends a response with the content of the file.
 		File(file string) error
 
@@ -199,6 +247,8 @@ type (
 		path     string
 		pnames   []string
 		pvalues  []string
+		hnames   []string
+		hvalues  []string
 		query    url.Values
 		handler  HandlerFunc
 		store    Map
@@ -349,6 +399,23 @@ func (c *context) SetParamValues(values ...string) {
 	}
 }
 
+func (c *context) HostParam(name string) string {
+	for i, n := range c.hnames {
+		if n == name {
+			return c.hvalues[i]
+		}
+	}
+	return ""
+}
+
+func (c *context) HostParamNames() []string {
+	return c.hnames
+}
+
+func (c *context) HostParamValues() []string {
+	return c.hvalues
+}
+
 func (c *context) QueryParam(name string) string {
 	if c.query == nil {
 		c.query = c.request.URL.Query()
@@ -410,6 +477,75 @@ func (c *context) Cookies() []*http.Cookie {
 	return c.request.Cookies()
 }
 
+func (c *context) SignedCookie(name string) (*http.Cookie, error) {
+	if c.echo.CookieKeyRing == nil {
+		return nil, ErrCookieKeyRingNotRegistered
+	}
+	cookie, err := c.request.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.echo.CookieKeyRing.Verify(name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	cookie.Value = value
+	return cookie, nil
+}
+
+func (c *context) SetSignedCookie(cookie *http.Cookie) error {
+	if c.echo.CookieKeyRing == nil {
+		return ErrCookieKeyRingNotRegistered
+	}
+	value, err := c.echo.CookieKeyRing.Sign(cookie.Name, cookie.Value, cookieExpires(cookie))
+	if err != nil {
+		return err
+	}
+	signed := *cookie
+	signed.Value = value
+	http.SetCookie(c.Response(), &signed)
+	return nil
+}
+
+func (c *context) EncryptedCookie(name string) (*http.Cookie, error) {
+	if c.echo.CookieKeyRing == nil {
+		return nil, ErrCookieKeyRingNotRegistered
+	}
+	cookie, err := c.request.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.echo.CookieKeyRing.Decrypt(name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	cookie.Value = value
+	return cookie, nil
+}
+
+func (c *context) SetEncryptedCookie(cookie *http.Cookie) error {
+	if c.echo.CookieKeyRing == nil {
+		return ErrCookieKeyRingNotRegistered
+	}
+	value, err := c.echo.CookieKeyRing.Encrypt(cookie.Name, cookie.Value, cookieExpires(cookie))
+	if err != nil {
+		return err
+	}
+	encrypted := *cookie
+	encrypted.Value = value
+	http.SetCookie(c.Response(), &encrypted)
+	return nil
+}
+
+// cookieExpires returns expiration time of the cookie so the client can not extend lifetime of signed or encrypted
+// value beyond it. Zero time is returned for session cookies.
+func cookieExpires(cookie *http.Cookie) time.Time {
+	if cookie.MaxAge > 0 {
+		return time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+	}
+	return cookie.Expires
+}
+
 func (c *context) Get(key string) interface{} {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -630,6 +766,8 @@ func (c *context) Reset(r *http.Request, w http.ResponseWriter) {
 	c.store = nil
 	c.path = ""
 	c.pnames = nil
+	c.hnames = nil
+	c.hvalues = c.hvalues[:0]
 	c.logger = nil
 	// NOTE: Don't reset because it has to have length c.echo.maxParam at all times
 	for i := 0; i < *c.echo.maxParam; i++ {
@@ -0,0 +1,239 @@
+package echo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+type (
+	// byteRange is satisfiable range of content in bytes.
+	byteRange struct {
+		start  int64
+		length int64
+	}
+
+	// countingWriter counts bytes written to it.
+	countingWriter int64
+)
+
+func (c *context) BlobRange(contentType string, b []byte) error {
+	return c.StreamRangeAt(contentType, bytes.NewReader(b), int64(len(b)))
+}
+
+func (c *context) StreamRange(contentType string, content io.ReadSeeker) error {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	return c.serveRange(contentType, size, func(offset, length int64) (io.Reader, error) {
+		if _, err := content.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.LimitReader(content, length), nil
+	})
+}
+
+func (c *context) StreamRangeAt(contentType string, content io.ReaderAt, size int64) error {
+	return c.serveRange(contentType, size, func(offset, length int64) (io.Reader, error) {
+		return io.NewSectionReader(content, offset, length), nil
+	})
+}
+
+// serveRange writes whole content, single range or multiple ranges as `multipart/byteranges` response depending on
+// `Range` and `If-Range` request headers. `ETag` and `Last-Modified` headers already set on response are used to
+// evaluate `If-Range`. Syntactically invalid ranges are ignored and whole content is sent as RFC 7233 allows.
+func (c *context) serveRange(contentType string, size int64, open func(offset, length int64) (io.Reader, error)) error {
+	if contentType == "" {
+		contentType = MIMEOctetStream
+	}
+	req := c.request
+	header := c.response.Header()
+	header.Set(HeaderAcceptRanges, "bytes")
+
+	var ranges []byteRange
+	if rangeHeader := req.Header.Get(HeaderRange); rangeHeader != "" && checkIfRange(req.Header, header) {
+		var ok bool
+		ranges, ok = parseByteRanges(rangeHeader, size)
+		if ok && len(ranges) == 0 {
+			header.Set(HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+			return ErrRangeNotSatisfiable
+		}
+		var total int64
+		for _, r := range ranges {
+			total += r.length
+		}
+		if total > size {
+			// overlapping ranges requesting more than the content itself are served as whole content
+			ranges = nil
+		}
+	}
+
+	send := req.Method != http.MethodHead
+	switch len(ranges) {
+	case 0:
+		c.writeContentType(contentType)
+		header.Set(HeaderContentLength, strconv.FormatInt(size, 10))
+		c.response.WriteHeader(http.StatusOK)
+		if !send {
+			return nil
+		}
+		return copyRange(c.response, open, byteRange{start: 0, length: size})
+	case 1:
+		r := ranges[0]
+		c.writeContentType(contentType)
+		header.Set(HeaderContentRange, r.contentRange(size))
+		header.Set(HeaderContentLength, strconv.FormatInt(r.length, 10))
+		c.response.WriteHeader(http.StatusPartialContent)
+		if !send {
+			return nil
+		}
+		return copyRange(c.response, open, r)
+	}
+
+	var length countingWriter
+	mw := multipart.NewWriter(&length)
+	for _, r := range ranges {
+		if _, err := mw.CreatePart(r.partHeader(contentType, size)); err != nil {
+			return err
+		}
+		length += countingWriter(r.length)
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	header.Set(HeaderContentType, "multipart/byteranges; boundary="+mw.Boundary())
+	header.Set(HeaderContentLength, strconv.FormatInt(int64(length), 10))
+	c.response.WriteHeader(http.StatusPartialContent)
+	if !send {
+		return nil
+	}
+
+	boundary := mw.Boundary()
+	mw = multipart.NewWriter(c.response)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		part, err := mw.CreatePart(r.partHeader(contentType, size))
+		if err != nil {
+			return err
+		}
+		if err := copyRange(part, open, r); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+func copyRange(w io.Writer, open func(offset, length int64) (io.Reader, error), r byteRange) error {
+	src, err := open(r.start, r.length)
+	if err != nil {
+		return err
+	}
+	if closer, ok := src.(io.Closer); ok {
+		defer closer.Close()
+	}
+	_, err = io.CopyN(w, src, r.length)
+	return err
+}
+
+// checkIfRange returns true when `Range` request header should be evaluated. `If-Range` containing an entity tag
+// must match strong `ETag` of the response and `If-Range` containing a date must exactly match `Last-Modified`.
+func checkIfRange(reqHeader http.Header, resHeader http.Header) bool {
+	ifRange := strings.TrimSpace(reqHeader.Get(HeaderIfRange))
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		etag := resHeader.Get(HeaderETag)
+		return etag != "" && !strings.HasPrefix(etag, "W/") && etag == ifRange
+	}
+	since, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(resHeader.Get(HeaderLastModified))
+	return err == nil && lastModified.Equal(since)
+}
+
+// parseByteRanges parses `Range` header value for content of given size. Returned ok is false when the header is
+// not a valid bytes range set. Unsatisfiable ranges are left out so empty result with ok true means that none of
+// the ranges can be satisfied.
+func parseByteRanges(value string, size int64) (ranges []byteRange, ok bool) {
+	const unit = "bytes="
+	if !strings.HasPrefix(value, unit) {
+		return nil, false
+	}
+	ranges = []byteRange{}
+	specs := 0
+	for _, spec := range strings.Split(value[len(unit):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		specs++
+		i := strings.IndexByte(spec, '-')
+		if i < 0 {
+			return nil, false
+		}
+		first, last := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		if first == "" {
+			// suffix range `-N` selects last N bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			ranges = append(ranges, byteRange{start: size - n, length: n})
+			continue
+		}
+
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil || start < 0 {
+			return nil, false
+		}
+		end := size - 1
+		if last != "" {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+				return nil, false
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+		if start >= size {
+			continue
+		}
+		ranges = append(ranges, byteRange{start: start, length: end - start + 1})
+	}
+	return ranges, specs > 0
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r byteRange) partHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		HeaderContentRange: {r.contentRange(size)},
+		HeaderContentType:  {contentType},
+	}
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
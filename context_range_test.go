@@ -0,0 +1,221 @@
+package echo
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRangeContent = "0123456789abcdefghij"
+
+func TestContext_BlobRange(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		whenMethod         string
+		whenHeaders        map[string]string
+		givenETag          string
+		givenLastModified  string
+		expectStatus       int
+		expectBody         string
+		expectContentRange string
+		expectError        string
+	}{
+		{
+			name:         "ok, whole content without range",
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:               "ok, single range",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=2-5"},
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "2345",
+			expectContentRange: "bytes 2-5/20",
+		},
+		{
+			name:               "ok, open ended range",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=15-"},
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "fghij",
+			expectContentRange: "bytes 15-19/20",
+		},
+		{
+			name:               "ok, suffix range",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=-3"},
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "hij",
+			expectContentRange: "bytes 17-19/20",
+		},
+		{
+			name:               "ok, range end past content is truncated",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=18-100"},
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "ij",
+			expectContentRange: "bytes 18-19/20",
+		},
+		{
+			name:               "ok, HEAD request has no body",
+			whenMethod:         http.MethodHead,
+			whenHeaders:        map[string]string{HeaderRange: "bytes=2-5"},
+			expectStatus:       http.StatusPartialContent,
+			expectContentRange: "bytes 2-5/20",
+		},
+		{
+			name:         "ok, invalid range is ignored",
+			whenHeaders:  map[string]string{HeaderRange: "bytes=5-2"},
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:         "ok, unknown unit is ignored",
+			whenHeaders:  map[string]string{HeaderRange: "items=0-1"},
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:         "ok, overlapping ranges larger than content send whole content",
+			whenHeaders:  map[string]string{HeaderRange: "bytes=0-15,5-19"},
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:               "ok, If-Range with matching ETag",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=0-1", HeaderIfRange: `"v1"`},
+			givenETag:          `"v1"`,
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "01",
+			expectContentRange: "bytes 0-1/20",
+		},
+		{
+			name:         "ok, If-Range with changed ETag sends whole content",
+			whenHeaders:  map[string]string{HeaderRange: "bytes=0-1", HeaderIfRange: `"v1"`},
+			givenETag:    `"v2"`,
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:         "ok, If-Range with weak ETag sends whole content",
+			whenHeaders:  map[string]string{HeaderRange: "bytes=0-1", HeaderIfRange: `W/"v1"`},
+			givenETag:    `W/"v1"`,
+			expectStatus: http.StatusOK,
+			expectBody:   testRangeContent,
+		},
+		{
+			name:               "ok, If-Range with matching date",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=0-1", HeaderIfRange: "Wed, 21 Oct 2015 07:28:00 GMT"},
+			givenLastModified:  "Wed, 21 Oct 2015 07:28:00 GMT",
+			expectStatus:       http.StatusPartialContent,
+			expectBody:         "01",
+			expectContentRange: "bytes 0-1/20",
+		},
+		{
+			name:              "ok, If-Range with older date sends whole content",
+			whenHeaders:       map[string]string{HeaderRange: "bytes=0-1", HeaderIfRange: "Wed, 21 Oct 2015 07:28:00 GMT"},
+			givenLastModified: "Thu, 22 Oct 2015 07:28:00 GMT",
+			expectStatus:      http.StatusOK,
+			expectBody:        testRangeContent,
+		},
+		{
+			name:               "nok, unsatisfiable range",
+			whenHeaders:        map[string]string{HeaderRange: "bytes=20-30"},
+			expectContentRange: "bytes */20",
+			expectError:        "code=416, message=Requested Range Not Satisfiable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tc.whenMethod != "" {
+				method = tc.whenMethod
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			for k, v := range tc.whenHeaders {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			c := New().NewContext(req, rec)
+			if tc.givenETag != "" {
+				c.Response().Header().Set(HeaderETag, tc.givenETag)
+			}
+			if tc.givenLastModified != "" {
+				c.Response().Header().Set(HeaderLastModified, tc.givenLastModified)
+			}
+
+			err := c.BlobRange(MIMETextPlain, []byte(testRangeContent))
+
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				assert.False(t, c.Response().Committed)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectStatus, rec.Code)
+				assert.Equal(t, MIMETextPlain, rec.Header().Get(HeaderContentType))
+			}
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectContentRange, rec.Header().Get(HeaderContentRange))
+			assert.Equal(t, "bytes", rec.Header().Get(HeaderAcceptRanges))
+		})
+	}
+}
+
+func TestContext_StreamRangeMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRange, "bytes=0-1, 10-12,-2")
+	rec := httptest.NewRecorder()
+	c := New().NewContext(req, rec)
+
+	err := c.StreamRange(MIMETextPlain, strings.NewReader(testRangeContent))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get(HeaderContentType))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+	assert.Equal(t, rec.Header().Get(HeaderContentLength), strconv.Itoa(rec.Body.Len()))
+
+	expected := []struct {
+		contentRange string
+		body         string
+	}{
+		{contentRange: "bytes 0-1/20", body: "01"},
+		{contentRange: "bytes 10-12/20", body: "abc"},
+		{contentRange: "bytes 18-19/20", body: "ij"},
+	}
+	mr := multipart.NewReader(rec.Body, params["boundary"])
+	for _, e := range expected {
+		part, err := mr.NextPart()
+		if !assert.NoError(t, err) {
+			return
+		}
+		body, err := ioutil.ReadAll(part)
+		assert.NoError(t, err)
+		assert.Equal(t, e.contentRange, part.Header.Get(HeaderContentRange))
+		assert.Equal(t, MIMETextPlain, part.Header.Get(HeaderContentType))
+		assert.Equal(t, e.body, string(body))
+	}
+	_, err = mr.NextPart()
+	assert.Error(t, err)
+}
+
+func TestContext_StreamRangeAt(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRange, "bytes=3-4")
+	rec := httptest.NewRecorder()
+	c := New().NewContext(req, rec)
+
+	err := c.StreamRangeAt("", strings.NewReader(testRangeContent), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, MIMEOctetStream, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "bytes 3-4/10", rec.Header().Get(HeaderContentRange))
+	assert.Equal(t, "34", rec.Body.String())
+}
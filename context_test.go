@@ -460,6 +460,69 @@ func TestContextCookie(t *testing.T) {
 	assert.Contains(rec.Header().Get(HeaderSetCookie), "HttpOnly")
 }
 
+func TestContextSignedAndEncryptedCookie(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		setCookie func(c Context, cookie *http.Cookie) error
+		getCookie func(c Context, name string) (*http.Cookie, error)
+		expectErr error
+	}{
+		{
+			name:      "signed",
+			setCookie: Context.SetSignedCookie,
+			getCookie: Context.SignedCookie,
+			expectErr: ErrCookieSignatureInvalid,
+		},
+		{
+			name:      "encrypted",
+			setCookie: Context.SetEncryptedCookie,
+			getCookie: Context.EncryptedCookie,
+			expectErr: ErrCookieDecryptionFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			testify.Equal(t, ErrCookieKeyRingNotRegistered, tc.setCookie(c, &http.Cookie{Name: "user"}))
+			_, err := tc.getCookie(c, "user")
+			testify.Equal(t, ErrCookieKeyRingNotRegistered, err)
+
+			e.CookieKeyRing = &CookieKeyRing{}
+			testify.Equal(t, ErrCookieKeyRingEmpty, tc.setCookie(c, &http.Cookie{Name: "user"}))
+
+			e.CookieKeyRing, err = NewCookieKeyRing([]byte("0123456789abcdef"))
+			testify.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			err = tc.setCookie(c, &http.Cookie{Name: "user", Value: "Jon Snow", Path: "/", MaxAge: 60})
+			testify.NoError(t, err)
+			cookie := rec.Result().Cookies()[0]
+			testify.Equal(t, "/", cookie.Path)
+			testify.NotContains(t, cookie.Value, "Jon")
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(cookie)
+			c = e.NewContext(req, httptest.NewRecorder())
+			cookie, err = tc.getCookie(c, "user")
+			if testify.NoError(t, err) {
+				testify.Equal(t, "Jon Snow", cookie.Value)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "user", Value: "Jon Snow"})
+			c = e.NewContext(req, httptest.NewRecorder())
+			_, err = tc.getCookie(c, "user")
+			testify.Equal(t, tc.expectErr, err)
+
+			_, err = tc.getCookie(c, "missing")
+			testify.Equal(t, http.ErrNoCookie, err)
+		})
+	}
+}
+
 func TestContextPath(t *testing.T) {
 	e := New()
 	r := e.Router()
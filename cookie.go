@@ -0,0 +1,223 @@
+package echo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// CookieKeyRing holds secret keys used to sign and encrypt cookie values. Keys are ordered from newest to oldest.
+	// Only the newest key is used to sign and encrypt new values while all keys are tried when values are verified or
+	// decrypted. This allows keys to be rotated without invalidating cookies already issued to clients.
+	//
+	// CookieKeyRing is safe for concurrent use.
+	CookieKeyRing struct {
+		mutex sync.RWMutex
+		keys  []cookieKey
+	}
+
+	cookieKey struct {
+		hashKey []byte
+		aead    cipher.AEAD
+	}
+)
+
+const (
+	// cookieKeyMinLength is the minimal length of secret key accepted by CookieKeyRing.
+	cookieKeyMinLength = 16
+	// cookieExpiresLength is the length of expiration timestamp prefix in signed and encrypted payload.
+	cookieExpiresLength = 8
+)
+
+var errCookieKeyTooShort = errors.New("cookie key must be at least 16 bytes long")
+
+// NewCookieKeyRing creates a new instance of CookieKeyRing. Keys must be ordered from newest to oldest and be at least
+// 16 bytes long, 32 bytes long random keys are recommended. Signing and encryption keys are derived from every key.
+func NewCookieKeyRing(keys ...[]byte) (*CookieKeyRing, error) {
+	if len(keys) == 0 {
+		return nil, ErrCookieKeyRingEmpty
+	}
+	kr := &CookieKeyRing{}
+	for _, key := range keys {
+		ck, err := newCookieKey(key)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys = append(kr.keys, ck)
+	}
+	return kr, nil
+}
+
+// Rotate adds key as the newest key of the ring. Keys exceeding maxKeys are dropped starting from the oldest one.
+// When maxKeys is less than 1 no keys are dropped.
+func (kr *CookieKeyRing) Rotate(key []byte, maxKeys int) error {
+	ck, err := newCookieKey(key)
+	if err != nil {
+		return err
+	}
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+	kr.keys = append([]cookieKey{ck}, kr.keys...)
+	if maxKeys > 0 && len(kr.keys) > maxKeys {
+		kr.keys = kr.keys[:maxKeys]
+	}
+	return nil
+}
+
+func newCookieKey(key []byte) (cookieKey, error) {
+	if len(key) < cookieKeyMinLength {
+		return cookieKey{}, errCookieKeyTooShort
+	}
+	block, err := aes.NewCipher(deriveCookieKey(key, "echo cookie encryption"))
+	if err != nil {
+		return cookieKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return cookieKey{}, err
+	}
+	return cookieKey{
+		hashKey: deriveCookieKey(key, "echo cookie signing"),
+		aead:    aead,
+	}, nil
+}
+
+// deriveCookieKey derives 32 byte long key for given purpose so the same secret is never used for signing and
+// encryption.
+func deriveCookieKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Sign returns value signed with the newest key. Signature covers name so signed value can not be reused under other
+// name. Zero expires means that the signed value never expires. ErrCookieKeyRingEmpty is returned when the ring has
+// no keys.
+func (kr *CookieKeyRing) Sign(name, value string, expires time.Time) (string, error) {
+	payload := cookiePayload(value, expires)
+	kr.mutex.RLock()
+	if len(kr.keys) == 0 {
+		kr.mutex.RUnlock()
+		return "", ErrCookieKeyRingEmpty
+	}
+	sig := signCookie(kr.keys[0].hashKey, name, payload)
+	kr.mutex.RUnlock()
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Verify checks signed value created by `Sign` against all keys and returns the original value.
+// ErrCookieSignatureInvalid is returned for malformed or tampered values, ErrCookieExpired for expired ones and
+// ErrCookieKeyRingEmpty when the ring has no keys.
+func (kr *CookieKeyRing) Verify(name, signed string) (string, error) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", ErrCookieSignatureInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(signed[:i])
+	if err != nil {
+		return "", ErrCookieSignatureInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", ErrCookieSignatureInvalid
+	}
+
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+	if len(kr.keys) == 0 {
+		return "", ErrCookieKeyRingEmpty
+	}
+	for _, key := range kr.keys {
+		if hmac.Equal(sig, signCookie(key.hashKey, name, payload)) {
+			return parseCookiePayload(payload, ErrCookieSignatureInvalid)
+		}
+	}
+	return "", ErrCookieSignatureInvalid
+}
+
+// Encrypt returns value encrypted and authenticated with AES-GCM using the newest key. Name is bound to encrypted
+// value so it can not be reused under other name. Zero expires means that the encrypted value never expires.
+// ErrCookieKeyRingEmpty is returned when the ring has no keys.
+func (kr *CookieKeyRing) Encrypt(name, value string, expires time.Time) (string, error) {
+	kr.mutex.RLock()
+	if len(kr.keys) == 0 {
+		kr.mutex.RUnlock()
+		return "", ErrCookieKeyRingEmpty
+	}
+	aead := kr.keys[0].aead
+	kr.mutex.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+cookieExpiresLength+len(value)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	b := aead.Seal(nonce, nonce, cookiePayload(value, expires), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Decrypt decrypts value created by `Encrypt` trying all keys and returns the original value.
+// ErrCookieDecryptionFailed is returned for malformed, tampered or undecryptable values, ErrCookieExpired for
+// expired ones and ErrCookieKeyRingEmpty when the ring has no keys.
+func (kr *CookieKeyRing) Decrypt(name, encrypted string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrCookieDecryptionFailed
+	}
+
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+	if len(kr.keys) == 0 {
+		return "", ErrCookieKeyRingEmpty
+	}
+	for _, key := range kr.keys {
+		nonceSize := key.aead.NonceSize()
+		if len(b) < nonceSize {
+			return "", ErrCookieDecryptionFailed
+		}
+		payload, err := key.aead.Open(nil, b[:nonceSize], b[nonceSize:], []byte(name))
+		if err == nil {
+			return parseCookiePayload(payload, ErrCookieDecryptionFailed)
+		}
+	}
+	return "", ErrCookieDecryptionFailed
+}
+
+func signCookie(hashKey []byte, name string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// cookiePayload prefixes value with its expiration time as unix timestamp. Zero timestamp stands for no expiration.
+func cookiePayload(value string, expires time.Time) []byte {
+	b := make([]byte, cookieExpiresLength, cookieExpiresLength+len(value))
+	if !expires.IsZero() {
+		binary.BigEndian.PutUint64(b, uint64(expires.Unix()))
+	}
+	return append(b, value...)
+}
+
+// parseCookiePayload returns value of the payload created by `cookiePayload`. Payload too short to contain the
+// expiration time is reported with malformedErr.
+func parseCookiePayload(payload []byte, malformedErr error) (string, error) {
+	if len(payload) < cookieExpiresLength {
+		return "", malformedErr
+	}
+	expires := int64(binary.BigEndian.Uint64(payload[:cookieExpiresLength]))
+	if expires != 0 && time.Now().Unix() > expires {
+		return "", ErrCookieExpired
+	}
+	return string(payload[cookieExpiresLength:]), nil
+}
@@ -0,0 +1,170 @@
+package echo
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCookieKeyRing(t *testing.T) {
+	_, err := NewCookieKeyRing()
+	assert.Equal(t, ErrCookieKeyRingEmpty, err)
+
+	_, err = NewCookieKeyRing([]byte("0123456789abcdef"), []byte("short"))
+	assert.Equal(t, errCookieKeyTooShort, err)
+
+	kr, err := NewCookieKeyRing([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, errCookieKeyTooShort, kr.Rotate([]byte("short"), 0))
+}
+
+func TestCookieKeyRing_Sign(t *testing.T) {
+	kr, err := NewCookieKeyRing([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+
+	var testCases = []struct {
+		name        string
+		whenName    string
+		whenSigned  func(signed string) string
+		whenExpires time.Time
+		expectValue string
+		expectErr   error
+	}{
+		{
+			name:        "ok",
+			whenName:    "user",
+			expectValue: "jon",
+		},
+		{
+			name:        "ok, not expired yet",
+			whenName:    "user",
+			whenExpires: time.Now().Add(time.Minute),
+			expectValue: "jon",
+		},
+		{
+			name:        "nok, expired",
+			whenName:    "user",
+			whenExpires: time.Now().Add(-time.Minute),
+			expectErr:   ErrCookieExpired,
+		},
+		{
+			name:      "nok, signed for other name",
+			whenName:  "admin",
+			expectErr: ErrCookieSignatureInvalid,
+		},
+		{
+			name:     "nok, tampered value",
+			whenName: "user",
+			whenSigned: func(signed string) string {
+				return "AAAAAAAAAABhZG1pbg" + signed[strings.LastIndexByte(signed, '.'):]
+			},
+			expectErr: ErrCookieSignatureInvalid,
+		},
+		{
+			name:     "nok, missing signature",
+			whenName: "user",
+			whenSigned: func(signed string) string {
+				return signed[:strings.LastIndexByte(signed, '.')]
+			},
+			expectErr: ErrCookieSignatureInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signed, err := kr.Sign("user", "jon", tc.whenExpires)
+			assert.NoError(t, err)
+			if tc.whenSigned != nil {
+				signed = tc.whenSigned(signed)
+			}
+			value, err := kr.Verify(tc.whenName, signed)
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectValue, value)
+		})
+	}
+}
+
+func TestCookieKeyRing_Encrypt(t *testing.T) {
+	kr, err := NewCookieKeyRing([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+
+	encrypted, err := kr.Encrypt("user", "jon", time.Time{})
+	assert.NoError(t, err)
+	value, err := kr.Decrypt("user", encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", value)
+
+	_, err = kr.Decrypt("admin", encrypted)
+	assert.Equal(t, ErrCookieDecryptionFailed, err)
+
+	_, err = kr.Decrypt("user", "not-base64!")
+	assert.Equal(t, ErrCookieDecryptionFailed, err)
+
+	_, err = kr.Decrypt("user", encrypted[:len(encrypted)-2])
+	assert.Equal(t, ErrCookieDecryptionFailed, err)
+
+	encrypted, err = kr.Encrypt("user", "jon", time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	_, err = kr.Decrypt("user", encrypted)
+	assert.Equal(t, ErrCookieExpired, err)
+
+	// payload without expiration prefix is not a valid encrypted value
+	aead := kr.keys[0].aead
+	nonce := make([]byte, aead.NonceSize())
+	short := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte("abc"), []byte("user")))
+	_, err = kr.Decrypt("user", short)
+	assert.Equal(t, ErrCookieDecryptionFailed, err)
+}
+
+func TestCookieKeyRing_empty(t *testing.T) {
+	kr := &CookieKeyRing{}
+
+	_, err := kr.Sign("user", "jon", time.Time{})
+	assert.Equal(t, ErrCookieKeyRingEmpty, err)
+	_, err = kr.Encrypt("user", "jon", time.Time{})
+	assert.Equal(t, ErrCookieKeyRingEmpty, err)
+	_, err = kr.Verify("user", "AAAAAAAAAABqb24.c2ln")
+	assert.Equal(t, ErrCookieKeyRingEmpty, err)
+	_, err = kr.Decrypt("user", "AAAAAAAAAABqb24")
+	assert.Equal(t, ErrCookieKeyRingEmpty, err)
+
+	// key ring can be populated by rotation
+	assert.NoError(t, kr.Rotate([]byte("0123456789abcdef"), 0))
+	signed, err := kr.Sign("user", "jon", time.Time{})
+	assert.NoError(t, err)
+	value, err := kr.Verify("user", signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", value)
+}
+
+func TestCookieKeyRing_Rotate(t *testing.T) {
+	kr, err := NewCookieKeyRing([]byte("first-key-0123456"))
+	assert.NoError(t, err)
+	signed, err := kr.Sign("user", "jon", time.Time{})
+	assert.NoError(t, err)
+	encrypted, err := kr.Encrypt("user", "jon", time.Time{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, kr.Rotate([]byte("second-key-012345"), 2))
+	resigned, err := kr.Sign("user", "jon", time.Time{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, signed, resigned)
+
+	// older key still verifies
+	value, err := kr.Verify("user", signed)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", value)
+	value, err = kr.Decrypt("user", encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", value)
+
+	// oldest key is dropped
+	assert.NoError(t, kr.Rotate([]byte("third-key-0123456"), 2))
+	_, err = kr.Verify("user", signed)
+	assert.Equal(t, ErrCookieSignatureInvalid, err)
+	_, err = kr.Decrypt("user", encrypted)
+	assert.Equal(t, ErrCookieDecryptionFailed, err)
+}
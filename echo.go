@@ -75,6 +75,8 @@ type (
 		maxParam         *int
 		router           *Router
 		routers          map[string]*Router
+		hostPatterns     []*hostPattern
+		routeInfos       map[string]routeInfo
 		notFoundHandler  HandlerFunc
 		pool             sync.Pool
 		Server           *http.Server
@@ -94,6 +96,8 @@ type (
 		Logger           Logger
 		IPExtractor      IPExtractor
 		ListenerNetwork  string
+		CookieKeyRing    *CookieKeyRing
+		websockets       webSocketRegistry
 	}
 
 	// Route contains a handler and information for matching against requests.
@@ -103,6 +107,15 @@ type (
 		Name   string `json:"name"`
 	}
 
+	// routeInfo is the handler and middleware of registered route used for route introspection.
+	routeInfo struct {
+		handler    HandlerFunc
+		group      *Group
+		middleware []MiddlewareFunc
+		// signature is set for routes registered with AddTyped
+		signature *TypedSignature
+	}
+
 	// HTTPError represents an error that occurred while handling a request.
 	HTTPError struct {
 		Code     int         `json:"-"`
@@ -190,21 +203,31 @@ const (
 const (
 	HeaderAccept         = "Accept"
 	HeaderAcceptEncoding = "Accept-Encoding"
+	HeaderAcceptRanges   = "Accept-Ranges"
 	// HeaderAllow is the name of the "Allow" header field used to list the set of methods
 	// advertised as supported by the target resource. Returning an Allow header is mandatory
 	// for status 405 (method not found) and useful for the OPTIONS method in responses.
 	// See RFC 7231: https://datatracker.ietf.org/doc/html/rfc7231#section-7.4.1
 	HeaderAllow               = "Allow"
 	HeaderAuthorization       = "Authorization"
+	HeaderCacheControl        = "Cache-Control"
+	HeaderConnection          = "Connection"
 	HeaderContentDisposition  = "Content-Disposition"
 	HeaderContentEncoding     = "Content-Encoding"
 	HeaderContentLength       = "Content-Length"
+	HeaderContentRange        = "Content-Range"
 	HeaderContentType         = "Content-Type"
 	HeaderCookie              = "Cookie"
 	HeaderSetCookie           = "Set-Cookie"
+	HeaderETag                = "ETag"
 	HeaderIfModifiedSince     = "If-Modified-Since"
+	HeaderIfUnmodifiedSince   = "If-Unmodified-Since"
+	HeaderIfMatch             = "If-Match"
+	HeaderIfNoneMatch         = "If-None-Match"
+	HeaderIfRange             = "If-Range"
 	HeaderLastModified        = "Last-Modified"
 	HeaderLocation            = "Location"
+	HeaderRange               = "Range"
 	HeaderRetryAfter          = "Retry-After"
 	HeaderUpgrade             = "Upgrade"
 	HeaderVary                = "Vary"
@@ -222,6 +245,13 @@ const (
 	HeaderServer              = "Server"
 	HeaderOrigin              = "Origin"
 
+	// WebSocket
+	HeaderSecWebSocketKey        = "Sec-WebSocket-Key"
+	HeaderSecWebSocketAccept     = "Sec-WebSocket-Accept"
+	HeaderSecWebSocketVersion    = "Sec-WebSocket-Version"
+	HeaderSecWebSocketProtocol   = "Sec-WebSocket-Protocol"
+	HeaderSecWebSocketExtensions = "Sec-WebSocket-Extensions"
+
 	// Access control
 	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
 	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
@@ -290,12 +320,29 @@ var (
 	ErrInternalServerError         = NewHTTPError(http.StatusInternalServerError)
 	ErrRequestTimeout              = NewHTTPError(http.StatusRequestTimeout)
 	ErrServiceUnavailable          = NewHTTPError(http.StatusServiceUnavailable)
+	ErrPreconditionFailed          = NewHTTPError(http.StatusPreconditionFailed)
+	ErrRangeNotSatisfiable         = NewHTTPError(http.StatusRequestedRangeNotSatisfiable)
+	ErrNotAcceptable               = NewHTTPError(http.StatusNotAcceptable)
+	ErrRouteNotFound               = errors.New("route not found")
+	ErrReverseParamMissing         = errors.New("missing route param")
+	ErrReverseParamUnknown         = errors.New("unknown route param")
+	ErrRouteHandlerMissing         = errors.New("route handler is nil")
+	ErrRouteInvalidPath            = errors.New("invalid route path")
+	ErrRouteDuplicate              = errors.New("route already registered")
+	ErrRouteParamConflict          = errors.New("conflicting route param name")
+	ErrMiddlewareNotFound          = errors.New("middleware not found")
+	ErrMiddlewareExists            = errors.New("middleware already exists")
 	ErrValidatorNotRegistered      = errors.New("validator not registered")
 	ErrRendererNotRegistered       = errors.New("renderer not registered")
 	ErrInvalidRedirectCode         = errors.New("invalid redirect status code")
 	ErrCookieNotFound              = errors.New("cookie not found")
 	ErrInvalidCertOrKeyType        = errors.New("invalid cert or key type, must be string or []byte")
 	ErrInvalidListenerNetwork      = errors.New("invalid listener network")
+	ErrCookieKeyRingNotRegistered  = errors.New("cookie key ring not registered")
+	ErrCookieKeyRingEmpty          = errors.New("cookie key ring has no keys")
+	ErrCookieSignatureInvalid      = errors.New("cookie signature is invalid")
+	ErrCookieExpired               = errors.New("cookie has expired")
+	ErrCookieDecryptionFailed      = errors.New("cookie could not be decrypted")
 )
 
 // Error handlers
@@ -341,6 +388,7 @@ func New() (e *Echo) {
 	}
 	e.router = NewRouter(e)
 	e.routers = map[string]*Router{}
+	e.routeInfos = map[string]routeInfo{}
 	return
 }
 
@@ -380,6 +428,10 @@ func (e *Echo) DefaultHTTPErrorHandler(err error, c Context) {
 	}
 
 	he, ok := err.(*HTTPError)
+	if be, isBindingError := err.(*BindingError); isBindingError && be.Source != "" {
+		// errors of DefaultBinder struct tags are bad requests, errors of ValueBinder are left to the handler
+		he, ok = be.HTTPError, true
+	}
 	if ok {
 		if he.Internal != nil {
 			if herr, ok := he.Internal.(*HTTPError); ok {
@@ -480,7 +532,8 @@ func (e *Echo) TRACE(path string, h HandlerFunc, m ...MiddlewareFunc) *Route {
 }
 
 // Any registers a new route for all HTTP methods and path with matching handler
-// in the router with optional route-level middleware.
+// in the router with optional route-level middleware. Only methods Echo has shortcuts for (and PROPFIND, REPORT)
+// are registered, use `Echo#Match` for other methods.
 func (e *Echo) Any(path string, handler HandlerFunc, middleware ...MiddlewareFunc) []*Route {
 	routes := make([]*Route, len(methods))
 	for i, m := range methods {
@@ -512,18 +565,45 @@ func (e *Echo) File(path, file string, m ...MiddlewareFunc) *Route {
 }
 
 func (e *Echo) add(host, method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
+	return e.addRoute(host, method, path, handler, nil, false, middleware...)
+}
+
+// addRoute registers route. Middleware of the group (when not nil) are run before route-level middleware. Placeholder
+// routes (see `Group#Use()`) do not replace already registered routes and can be replaced by routes registered later
+// in strict router mode.
+func (e *Echo) addRoute(host, method, path string, handler HandlerFunc, group *Group, placeholder bool, middleware ...MiddlewareFunc) *Route {
 	name := handlerName(handler)
 	router := e.findRouter(host)
-	router.Add(method, path, func(c Context) error {
+	// Copy into a new slice to avoid accidentally sharing the same slice between routes.
+	middleware = append([]MiddlewareFunc(nil), middleware...)
+	routeHandler := func(c Context) error {
 		h := applyMiddleware(handler, middleware...)
+		if group != nil {
+			h = applyMiddleware(h, group.loadChain()...)
+		}
 		return h(c)
-	})
+	}
+	strict := router.options().Strict
+	if handler == nil && strict {
+		routeHandler = nil // let router report route without handler
+	}
+	added, err := router.add(method, path, routeHandler, placeholder, strict)
+	if err != nil {
+		panic(err)
+	}
+	if !added {
+		return e.router.routes[host+method+path]
+	}
 	r := &Route{
 		Method: method,
 		Path:   path,
 		Name:   name,
 	}
-	e.router.routes[method+path] = r
+	e.router.routes[host+method+path] = r
+	e.routeInfos[host+method+path] = routeInfo{handler: handler, group: group, middleware: middleware}
+	if router != e.router {
+		router.routes[method+path] = r
+	}
 	return r
 }
 
@@ -534,8 +614,20 @@ func (e *Echo) Add(method, path string, handler HandlerFunc, middleware ...Middl
 }
 
 // Host creates a new router group for the provided host and optional host-level middleware.
+//
+// Name may be a pattern with wildcard (`*.example.com`) or named (`{tenant}.example.com`) labels each matching
+// exactly one label of the request host. Labels captured by the pattern are available with `Context#HostParam`.
+// Patterns ignore port and case of the request host and the most specific pattern (most literal labels) wins.
+// Requests not matching any host are routed by the default router.
+//
+// Host routers use CaseInsensitive, TrailingSlash and Strict options of the default router (`Echo#Router()`).
 func (e *Echo) Host(name string, m ...MiddlewareFunc) (g *Group) {
-	e.routers[name] = NewRouter(e)
+	router := NewRouter(e)
+	router.parent = e.router
+	if isHostPattern(name) {
+		e.addHostPattern(newHostPattern(name, router))
+	}
+	e.routers[name] = router
 	g = &Group{host: name, echo: e}
 	g.Use(m...)
 	return
@@ -560,6 +652,9 @@ func (e *Echo) URL(h HandlerFunc, params ...interface{}) string {
 }
 
 // Reverse generates an URL from route name and provided parameters.
+//
+// Params are used in order and are not escaped. Use `ReverseURL()` for escaping, named params, query strings and
+// error reporting.
 func (e *Echo) Reverse(name string, params ...interface{}) string {
 	uri := new(bytes.Buffer)
 	ln := len(params)
@@ -592,6 +687,32 @@ func (e *Echo) Routes() []*Route {
 	return routes
 }
 
+// RouteMiddleware returns names of middleware run for the route registered for host (empty for routes not registered
+// with `Echo#Host()`), method and path in order of execution: middleware added with `Echo#Pre()`, `Echo#Use()`,
+// middleware of groups (parent groups first) and route-level middleware. Middleware without name (see
+// `Group#UseNamed()`) are named by their function name. Returns error wrapping ErrRouteNotFound when route does not
+// exist.
+func (e *Echo) RouteMiddleware(host, method, path string) ([]string, error) {
+	rm, ok := e.routeInfos[host+method+path]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s%s", ErrRouteNotFound, method, host, path)
+	}
+	names := make([]string, 0, len(e.premiddleware)+len(e.middleware)+len(rm.middleware))
+	for _, m := range e.premiddleware {
+		names = append(names, middlewareName(m))
+	}
+	for _, m := range e.middleware {
+		names = append(names, middlewareName(m))
+	}
+	if rm.group != nil {
+		names = append(names, rm.group.middlewareNames()...)
+	}
+	for _, m := range rm.middleware {
+		names = append(names, middlewareName(m))
+	}
+	return names, nil
+}
+
 // AcquireContext returns an empty `Context` instance from the pool.
 // You must return the context by calling `ReleaseContext()`.
 func (e *Echo) AcquireContext() Context {
@@ -612,12 +733,12 @@ func (e *Echo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var h func(Context) error
 
 	if e.premiddleware == nil {
-		e.findRouter(r.Host).Find(r.Method, GetPath(r), c)
+		e.matchRouter(r.Host, c).Find(r.Method, GetPath(r), c)
 		h = c.Handler()
 		h = applyMiddleware(h, e.middleware...)
 	} else {
 		h = func(c Context) error {
-			e.findRouter(r.Host).Find(r.Method, GetPath(r), c)
+			e.matchRouter(r.Host, c).Find(r.Method, GetPath(r), c)
 			h := c.Handler()
 			h = applyMiddleware(h, e.middleware...)
 			return h(c)
@@ -825,6 +946,7 @@ func (e *Echo) StartH2CServer(address string, h2s *http2.Server) (err error) {
 func (e *Echo) Close() error {
 	e.startupMutex.Lock()
 	defer e.startupMutex.Unlock()
+	defer e.websockets.closeAll(WebSocketCloseGoingAway, "server closed")
 	if err := e.TLSServer.Close(); err != nil {
 		return err
 	}
@@ -832,10 +954,12 @@ func (e *Echo) Close() error {
 }
 
 // Shutdown stops the server gracefully.
-// It internally calls `http.Server#Shutdown()`.
+// It internally calls `http.Server#Shutdown()`. WebSocket connections, which are not tracked by `http.Server`, are
+// sent a close frame with status 1001 (going away) and closed.
 func (e *Echo) Shutdown(ctx stdContext.Context) error {
 	e.startupMutex.Lock()
 	defer e.startupMutex.Unlock()
+	defer e.websockets.closeAll(WebSocketCloseGoingAway, "server shutdown")
 	if err := e.TLSServer.Shutdown(ctx); err != nil {
 		return err
 	}
@@ -913,6 +1037,10 @@ func (e *Echo) findRouter(host string) *Router {
 	return e.router
 }
 
+func middlewareName(m MiddlewareFunc) string {
+	return runtime.FuncForPC(reflect.ValueOf(m).Pointer()).Name()
+}
+
 func handlerName(h HandlerFunc) string {
 	t := reflect.ValueOf(h).Type()
 	if t.Kind() == reflect.Func {
@@ -1132,6 +1132,16 @@ func TestDefaultHTTPErrorHandler(t *testing.T) {
 		c.String(http.StatusOK, "OK")
 		return errors.New("ERROR")
 	})
+	e.GET("/binding-error", func(c Context) error {
+		var target struct {
+			ID string `query:"id,required"`
+		}
+		return c.Bind(&target)
+	})
+	e.GET("/value-binder-error", func(c Context) error {
+		var id int64
+		return QueryParamsBinder(c).MustInt64("id", &id).BindError()
+	})
 	e.GET("/internal-error", func(c Context) error {
 		err := errors.New("internal error message body")
 		return NewHTTPError(http.StatusBadRequest).SetInternal(err)
@@ -1170,6 +1180,13 @@ func TestDefaultHTTPErrorHandler(t *testing.T) {
 	c, b = request(http.MethodGet, "/servererror", e)
 	assert.Equal(t, http.StatusInternalServerError, c)
 	assert.Equal(t, "{\"code\":33,\"error\":\"stackinfo\",\"message\":\"Something bad happened\"}\n", b)
+	// binding errors have status code of their HTTPError
+	c, b = request(http.MethodGet, "/binding-error", e)
+	assert.Equal(t, http.StatusBadRequest, c)
+	assert.Equal(t, "{\"message\":\"required field value is empty\"}\n", b)
+	// errors of ValueBinder are not converted
+	c, _ = request(http.MethodGet, "/value-binder-error", e)
+	assert.Equal(t, http.StatusInternalServerError, c)
 }
 
 func TestEchoClose(t *testing.T) {
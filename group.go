@@ -1,7 +1,9 @@
 package echo
 
 import (
+	"fmt"
 	"net/http"
+	"sync/atomic"
 )
 
 type (
@@ -12,21 +14,161 @@ type (
 		common
 		host       string
 		prefix     string
-		middleware []MiddlewareFunc
+		middleware []namedMiddleware
 		echo       *Echo
+		parent     *Group
+		children   []*Group
+		// chain holds the effective middleware of the group ([]MiddlewareFunc with middleware of parent groups
+		// followed by own middleware). Routes read it when handling request so middleware changes apply also to
+		// already registered routes. It is atomic.Value so middleware can be changed while server is serving
+		// requests without data race.
+		chain atomic.Value
+	}
+
+	namedMiddleware struct {
+		name       string
+		middleware MiddlewareFunc
 	}
 )
 
 // Use implements `Echo#Use()` for sub-routes within the Group.
+//
+// Group middleware apply to all routes of the group and its sub-groups including routes registered before the
+// middleware was added.
 func (g *Group) Use(middleware ...MiddlewareFunc) {
-	g.middleware = append(g.middleware, middleware...)
-	if len(g.middleware) == 0 {
+	for _, m := range middleware {
+		g.middleware = append(g.middleware, namedMiddleware{middleware: m})
+	}
+	g.updateChain()
+	g.addPlaceholderRoutes()
+}
+
+// UseNamed adds named middleware to the group. Name can be used to insert other middleware before or after it
+// (see `InsertMiddlewareBefore()`, `InsertMiddlewareAfter()`) or to remove it (see `RemoveMiddleware()`) and is listed
+// by `Echo#RouteMiddleware()`. It panics when name is empty or already used in the group.
+func (g *Group) UseNamed(name string, middleware MiddlewareFunc) {
+	if name == "" {
+		panic("echo: middleware name must not be empty")
+	}
+	if g.middlewareIndex(name) != -1 {
+		panic(fmt.Sprintf("echo: middleware %q already exists in group %q", name, g.prefix))
+	}
+	g.middleware = append(g.middleware, namedMiddleware{name: name, middleware: middleware})
+	g.updateChain()
+	g.addPlaceholderRoutes()
+}
+
+// InsertMiddlewareBefore inserts middleware with newName before the group middleware with given name. Empty newName
+// adds unnamed middleware. Returns error wrapping ErrMiddlewareNotFound when group has no middleware with given name
+// and ErrMiddlewareExists when newName is already used in the group.
+//
+// Changes apply to routes registered before and after the change. Middleware can be changed while server is running,
+// requests being served keep the middleware they started with. Changes of middleware must not be done concurrently
+// with each other or with route registration.
+func (g *Group) InsertMiddlewareBefore(name, newName string, middleware MiddlewareFunc) error {
+	return g.insertMiddleware(name, 0, newName, middleware)
+}
+
+// InsertMiddlewareAfter inserts middleware with newName after the group middleware with given name.
+// See `InsertMiddlewareBefore()`.
+func (g *Group) InsertMiddlewareAfter(name, newName string, middleware MiddlewareFunc) error {
+	return g.insertMiddleware(name, 1, newName, middleware)
+}
+
+// RemoveMiddleware removes named middleware from the group. Returns error wrapping ErrMiddlewareNotFound when group
+// has no middleware with given name.
+func (g *Group) RemoveMiddleware(name string) error {
+	i := g.middlewareIndex(name)
+	if i == -1 {
+		return fmt.Errorf("%w: %q", ErrMiddlewareNotFound, name)
+	}
+	g.middleware = append(g.middleware[:i:i], g.middleware[i+1:]...)
+	g.updateChain()
+	return nil
+}
+
+func (g *Group) insertMiddleware(name string, offset int, newName string, middleware MiddlewareFunc) error {
+	if newName != "" && g.middlewareIndex(newName) != -1 {
+		return fmt.Errorf("%w: %q", ErrMiddlewareExists, newName)
+	}
+	i := g.middlewareIndex(name)
+	if i == -1 {
+		return fmt.Errorf("%w: %q", ErrMiddlewareNotFound, name)
+	}
+	i += offset
+
+	m := make([]namedMiddleware, 0, len(g.middleware)+1)
+	m = append(m, g.middleware[:i]...)
+	m = append(m, namedMiddleware{name: newName, middleware: middleware})
+	m = append(m, g.middleware[i:]...)
+	g.middleware = m
+	g.updateChain()
+	return nil
+}
+
+func (g *Group) middlewareIndex(name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, m := range g.middleware {
+		if m.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateChain rebuilds effective middleware of the group and its sub-groups. New slice is created so requests being
+// served with the previous chain are not affected.
+func (g *Group) updateChain() {
+	var chain []MiddlewareFunc
+	if g.parent != nil {
+		chain = append(chain, g.parent.loadChain()...)
+	}
+	for _, m := range g.middleware {
+		chain = append(chain, m.middleware)
+	}
+	g.chain.Store(chain)
+	for _, child := range g.children {
+		child.updateChain()
+	}
+}
+
+// loadChain returns effective middleware of the group.
+func (g *Group) loadChain() []MiddlewareFunc {
+	chain, _ := g.chain.Load().([]MiddlewareFunc)
+	return chain
+}
+
+// middlewareNames returns names of the effective middleware of the group. Unnamed middleware are named by their
+// function name.
+func (g *Group) middlewareNames() []string {
+	var names []string
+	if g.parent != nil {
+		names = g.parent.middlewareNames()
+	}
+	for _, m := range g.middleware {
+		name := m.name
+		if name == "" {
+			name = middlewareName(m.middleware)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (g *Group) addPlaceholderRoutes() {
+	if len(g.loadChain()) == 0 {
 		return
 	}
 	// Allow all requests to reach the group as they might get dropped if router
 	// doesn't find a match, making none of the group middleware process.
-	g.Any("", NotFoundHandler)
-	g.Any("/*", NotFoundHandler)
+	// These are placeholder routes so they do not conflict with group routes in strict router mode.
+	for _, path := range []string{"", "/*"} {
+		for _, method := range methods {
+			g.echo.addRoute(g.host, method, g.prefix+path, NotFoundHandler, g, true)
+		}
+	}
 }
 
 // CONNECT implements `Echo#CONNECT()` for sub-routes within the Group.
@@ -92,13 +234,12 @@ func (g *Group) Match(methods []string, path string, handler HandlerFunc, middle
 	return routes
 }
 
-// Group creates a new sub-group with prefix and optional sub-group-level middleware.
+// Group creates a new sub-group with prefix and optional sub-group-level middleware. Sub-group routes run middleware
+// of the group (also middleware added to the group later) before sub-group middleware.
 func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) (sg *Group) {
-	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
-	m = append(m, g.middleware...)
-	m = append(m, middleware...)
-	sg = g.echo.Group(g.prefix+prefix, m...)
-	sg.host = g.host
+	sg = &Group{host: g.host, prefix: g.prefix + prefix, echo: g.echo, parent: g}
+	g.children = append(g.children, sg)
+	sg.Use(middleware...)
 	return
 }
 
@@ -109,11 +250,5 @@ func (g *Group) File(path, file string) {
 
 // Add implements `Echo#Add()` for sub-routes within the Group.
 func (g *Group) Add(method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
-	// Combine into a new slice to avoid accidentally passing the same slice for
-	// multiple routes, which would lead to later add() calls overwriting the
-	// middleware from earlier calls.
-	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
-	m = append(m, g.middleware...)
-	m = append(m, middleware...)
-	return g.echo.add(g.host, method, g.prefix+path, handler, m...)
+	return g.echo.addRoute(g.host, method, g.prefix+path, handler, g, false, middleware...)
 }
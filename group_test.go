@@ -1,9 +1,11 @@
 package echo
 
 import (
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -119,3 +121,134 @@ func TestGroupRouteMiddlewareWithMatchAny(t *testing.T) {
 	assert.Equal(t, "/*", m)
 
 }
+
+func TestGroupUseAfterRoutes(t *testing.T) {
+	e := New()
+	g := e.Group("/group")
+	h := func(c Context) error {
+		return c.String(http.StatusOK, "handler "+c.Path())
+	}
+	g.GET("/*", h)
+	g.GET("", h)
+	g.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Response().Header().Set("X-Middleware", "m")
+			return next(c)
+		}
+	})
+
+	// placeholder routes of group middleware do not replace routes registered before
+	for _, path := range []string{"/group/anything", "/group"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "handler /group")
+		assert.Equal(t, "m", rec.Header().Get("X-Middleware"))
+	}
+}
+
+func TestGroupNamedMiddleware(t *testing.T) {
+	trace := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c Context) error {
+				c.Response().Header().Add("X-Trace", name)
+				return next(c)
+			}
+		}
+	}
+	request := func(e *Echo, path string) []string {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Header().Values("X-Trace")
+	}
+	h := func(c Context) error { return c.NoContent(http.StatusOK) }
+
+	e := New()
+	g := e.Group("/api")
+	g.UseNamed("auth", trace("auth"))
+	g.GET("/before", h, trace("route"))
+	sg := g.Group("/v1")
+	sg.UseNamed("v1", trace("v1"))
+	sg.GET("/users", h)
+
+	assert.Equal(t, []string{"auth", "route"}, request(e, "/api/before"))
+	assert.Equal(t, []string{"auth", "v1"}, request(e, "/api/v1/users"))
+
+	// changes apply to routes registered before and after the change and to sub-groups
+	assert.NoError(t, g.InsertMiddlewareBefore("auth", "cors", trace("cors")))
+	assert.NoError(t, g.InsertMiddlewareAfter("auth", "", trace("audit")))
+	g.GET("/after", h)
+
+	assert.Equal(t, []string{"cors", "auth", "audit", "route"}, request(e, "/api/before"))
+	assert.Equal(t, []string{"cors", "auth", "audit"}, request(e, "/api/after"))
+	assert.Equal(t, []string{"cors", "auth", "audit", "v1"}, request(e, "/api/v1/users"))
+
+	names, err := e.RouteMiddleware("", http.MethodGet, "/api/v1/users")
+	assert.NoError(t, err)
+	assert.Len(t, names, 4)
+	assert.Equal(t, []string{"cors", "auth"}, names[:2])
+	assert.Contains(t, names[2], "TestGroupNamedMiddleware")
+	assert.Equal(t, "v1", names[3])
+
+	assert.NoError(t, g.RemoveMiddleware("auth"))
+	assert.Equal(t, []string{"cors", "audit", "route"}, request(e, "/api/before"))
+	assert.Equal(t, []string{"cors", "audit", "v1"}, request(e, "/api/v1/users"))
+	// group middleware run also for paths without route
+	assert.Equal(t, []string{"cors", "audit"}, request(e, "/api/missing"))
+}
+
+func TestGroupNamedMiddleware_errors(t *testing.T) {
+	g := New().Group("/api")
+	m := func(next HandlerFunc) HandlerFunc { return next }
+	g.UseNamed("auth", m)
+
+	err := g.InsertMiddlewareBefore("missing", "new", m)
+	assert.EqualError(t, err, `middleware not found: "missing"`)
+	assert.True(t, errors.Is(err, ErrMiddlewareNotFound))
+
+	err = g.InsertMiddlewareAfter("auth", "auth", m)
+	assert.True(t, errors.Is(err, ErrMiddlewareExists))
+
+	assert.True(t, errors.Is(g.RemoveMiddleware("missing"), ErrMiddlewareNotFound))
+
+	assert.Panics(t, func() { g.UseNamed("auth", m) })
+	assert.Panics(t, func() { g.UseNamed("", m) })
+}
+
+func TestGroupNamedMiddleware_changeWhileServing(t *testing.T) {
+	m := func(next HandlerFunc) HandlerFunc { return next }
+	e := New()
+	g := e.Group("/api")
+	g.UseNamed("auth", m)
+	g.GET("/users", func(c Context) error { return c.NoContent(http.StatusOK) })
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, g.InsertMiddlewareAfter("auth", "audit", m))
+		assert.NoError(t, g.RemoveMiddleware("audit"))
+	}
+	wg.Wait()
+}
+
+func TestEchoRouteMiddleware(t *testing.T) {
+	e := New()
+	e.Pre(func(next HandlerFunc) HandlerFunc { return next })
+	e.GET("/", func(c Context) error { return nil })
+
+	names, err := e.RouteMiddleware("", http.MethodGet, "/")
+	assert.NoError(t, err)
+	assert.Len(t, names, 1)
+	assert.Contains(t, names[0], "TestEchoRouteMiddleware.func1")
+
+	_, err = e.RouteMiddleware("", http.MethodPost, "/")
+	assert.True(t, errors.Is(err, ErrRouteNotFound))
+}
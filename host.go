@@ -0,0 +1,123 @@
+package echo
+
+import (
+	"sort"
+	"strings"
+)
+
+// hostPattern is a virtual host name with wildcard (`*`) or named (`{name}`) labels. Each of them matches exactly
+// one label of the request host.
+type hostPattern struct {
+	pattern  string
+	labels   []string
+	names    []string
+	literals int
+	router   *Router
+}
+
+// isHostPattern returns true when the host name passed to `Echo#Host` contains wildcard or named labels.
+func isHostPattern(name string) bool {
+	return strings.ContainsAny(name, "*{}")
+}
+
+// newHostPattern parses host pattern. It panics when the pattern is invalid as it is a programming error.
+func newHostPattern(pattern string, router *Router) *hostPattern {
+	if strings.ContainsAny(pattern, ":/") {
+		panic("echo: host pattern must not contain port or path: " + pattern)
+	}
+	p := &hostPattern{pattern: pattern, router: router}
+	for _, label := range strings.Split(strings.ToLower(strings.TrimSuffix(pattern, ".")), ".") {
+		switch {
+		case label == "*":
+			p.names = append(p.names, "*")
+		case strings.HasPrefix(label, "{") && strings.HasSuffix(label, "}") && len(label) > 2 &&
+			!strings.ContainsAny(label[1:len(label)-1], "*{}"):
+			p.names = append(p.names, label[1:len(label)-1])
+		case label == "" || strings.ContainsAny(label, "*{}"):
+			panic("echo: invalid host pattern: " + pattern)
+		default:
+			p.literals++
+		}
+		p.labels = append(p.labels, label)
+	}
+	return p
+}
+
+// match matches the host (without port) against the pattern appending captured labels to values.
+func (p *hostPattern) match(host string, values []string) ([]string, bool) {
+	if strings.Count(host, ".")+1 != len(p.labels) {
+		return values, false
+	}
+	for _, label := range p.labels {
+		part := host
+		if i := strings.IndexByte(host, '.'); i >= 0 {
+			part, host = host[:i], host[i+1:]
+		}
+		if label[0] == '*' || label[0] == '{' {
+			if part == "" {
+				return values, false
+			}
+			values = append(values, part)
+		} else if label != part {
+			return values, false
+		}
+	}
+	return values, true
+}
+
+// addHostPattern registers the pattern keeping patterns ordered from the most specific one (most literal labels,
+// then most labels) so the first match is the best one.
+func (e *Echo) addHostPattern(p *hostPattern) {
+	for i, existing := range e.hostPatterns {
+		if existing.pattern == p.pattern {
+			e.hostPatterns[i] = p
+			return
+		}
+	}
+	e.hostPatterns = append(e.hostPatterns, p)
+	sort.SliceStable(e.hostPatterns, func(i, j int) bool {
+		a, b := e.hostPatterns[i], e.hostPatterns[j]
+		if a.literals != b.literals {
+			return a.literals > b.literals
+		}
+		return len(a.labels) > len(b.labels)
+	})
+}
+
+// matchRouter returns router for the request host. Exact host names are checked first (with and without port), then
+// host patterns ignoring port and case. Labels captured by the matched pattern are set to the context.
+func (e *Echo) matchRouter(host string, c Context) *Router {
+	if len(e.routers) == 0 {
+		return e.router
+	}
+	if r, ok := e.routers[host]; ok {
+		return r
+	}
+	hostname := stripHostPort(host)
+	if hostname != host {
+		if r, ok := e.routers[hostname]; ok {
+			return r
+		}
+	}
+	if len(e.hostPatterns) == 0 {
+		return e.router
+	}
+
+	ctx := c.(*context)
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for _, p := range e.hostPatterns {
+		if values, ok := p.match(hostname, ctx.hvalues[:0]); ok {
+			ctx.hnames = p.names
+			ctx.hvalues = values
+			return p.router
+		}
+	}
+	return e.router
+}
+
+func stripHostPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i > strings.LastIndexByte(host, ']') {
+		return host[:i]
+	}
+	return host
+}
@@ -0,0 +1,160 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoHost_patterns(t *testing.T) {
+	handler := func(name string) HandlerFunc {
+		return func(c Context) error {
+			values := make([]string, 0)
+			for _, n := range c.HostParamNames() {
+				values = append(values, n+"="+c.HostParam(n))
+			}
+			return c.String(http.StatusOK, name+" "+strings.Join(values, ","))
+		}
+	}
+
+	e := New()
+	e.GET("/", handler("default"))
+	e.Host("api.example.com").GET("/", handler("exact"))
+	e.Host("*.example.com").GET("/", handler("wildcard"))
+	e.Host("{tenant}.example.com").GET("/", handler("tenant"))
+	e.Host("{tenant}.eu.example.com").GET("/", handler("eu"))
+	e.Host("{service}.{tenant}.example.com").GET("/", handler("service"))
+	e.Host("admin.{tenant}.example.com").GET("/", handler("admin"))
+
+	var testCases = []struct {
+		name       string
+		whenHost   string
+		expectBody string
+	}{
+		{
+			name:       "ok, exact host",
+			whenHost:   "api.example.com",
+			expectBody: "exact ",
+		},
+		{
+			name:       "ok, exact host with port",
+			whenHost:   "api.example.com:8080",
+			expectBody: "exact ",
+		},
+		{
+			name:       "ok, first registered pattern wins among equally specific",
+			whenHost:   "acme.example.com",
+			expectBody: "wildcard *=acme",
+		},
+		{
+			name:       "ok, port and case are ignored",
+			whenHost:   "ACME.Example.com:1323",
+			expectBody: "wildcard *=acme",
+		},
+		{
+			name:       "ok, most specific pattern",
+			whenHost:   "acme.eu.example.com",
+			expectBody: "eu tenant=acme",
+		},
+		{
+			name:       "ok, literal label is preferred",
+			whenHost:   "admin.acme.example.com",
+			expectBody: "admin tenant=acme",
+		},
+		{
+			name:       "ok, multiple params",
+			whenHost:   "shop.acme.example.com",
+			expectBody: "service service=shop,tenant=acme",
+		},
+		{
+			name:       "ok, trailing dot",
+			whenHost:   "acme.example.com.",
+			expectBody: "wildcard *=acme",
+		},
+		{
+			name:       "ok, label matches only one label",
+			whenHost:   "a.b.c.example.com",
+			expectBody: "default ",
+		},
+		{
+			name:       "ok, no match falls back to default router",
+			whenHost:   "example.com",
+			expectBody: "default ",
+		},
+		{
+			name:       "ok, IPv6 host",
+			whenHost:   "[::1]:8080",
+			expectBody: "default ",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tc.whenHost
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+func TestEchoHost_patternReRegistered(t *testing.T) {
+	e := New()
+	e.Host("{tenant}.example.com").GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "first")
+	})
+	e.Host("{tenant}.example.com").GET("/", func(c Context) error {
+		return c.String(http.StatusOK, c.HostParam("tenant"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "acme.example.com"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "acme", rec.Body.String())
+	assert.Len(t, e.hostPatterns, 1)
+}
+
+func TestEchoHost_invalidPattern(t *testing.T) {
+	var testCases = []string{
+		"{}.example.com",
+		"{tenant.example.com",
+		"a*b.example.com",
+		"*.example.com:8080",
+		"*..example.com",
+		"{a{b}}.example.com",
+	}
+
+	for _, pattern := range testCases {
+		t.Run(pattern, func(t *testing.T) {
+			assert.Panics(t, func() {
+				New().Host(pattern)
+			})
+		})
+	}
+}
+
+func TestContext_HostParam(t *testing.T) {
+	e := New()
+	c := e.NewContext(nil, nil).(*context)
+
+	assert.Equal(t, "", c.HostParam("tenant"))
+	assert.Empty(t, c.HostParamNames())
+
+	c.hnames = []string{"tenant"}
+	c.hvalues = []string{"acme"}
+	assert.Equal(t, "acme", c.HostParam("tenant"))
+	assert.Equal(t, []string{"acme"}, c.HostParamValues())
+
+	c.Reset(nil, nil)
+	assert.Equal(t, "", c.HostParam("tenant"))
+	assert.Empty(t, c.HostParamValues())
+}
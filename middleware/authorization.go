@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// AuthorizationConfig defines the config for Authorization middleware.
+	AuthorizationConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// PrincipalExtractor returns the principal of the request. It is usually built on value stored into context by
+		// authentication middleware (see `JWTPrincipalExtractor`, `BasicAuthPrincipalExtractor` and
+		// `KeyAuthPrincipalExtractor`).
+		// Required.
+		PrincipalExtractor PrincipalExtractor
+
+		// Rules the principal has to satisfy to access the route. All rules must allow access.
+		// Required.
+		Rules []AuthorizationRule
+
+		// DecisionHandler is called with every authorization decision.
+		// Optional. Default value logs denials as warnings and granted access as debug messages with `Context#Logger()`.
+		DecisionHandler AuthorizationDecisionHandler
+
+		// Context key to store the principal into context.
+		// Optional. Default value "principal".
+		ContextKey string
+	}
+
+	// Principal describes the authenticated caller of the request.
+	Principal struct {
+		// ID identifies the principal, i.e. user ID, username or API key name.
+		ID string
+		// Roles are roles granted to the principal (RBAC).
+		Roles []string
+		// Scopes are permissions granted to the principal, i.e. OAuth2 scopes.
+		Scopes []string
+		// Attributes are arbitrary attributes of the principal used by policy expressions (ABAC).
+		Attributes map[string]interface{}
+	}
+
+	// PrincipalExtractor defines a function to extract the principal from the request.
+	PrincipalExtractor func(c echo.Context) (*Principal, error)
+
+	// AuthorizationRule defines a function to decide whether the principal may access the route. Returned error
+	// describes the reason of denial. It is logged but never sent to the client.
+	AuthorizationRule func(c echo.Context, p *Principal) error
+
+	// AuthorizationDecision describes the outcome of authorization of a request.
+	AuthorizationDecision struct {
+		Allowed   bool
+		Principal *Principal
+		Method    string
+		Path      string
+		// Reason is the reason of denial. Empty when access was allowed.
+		Reason string
+	}
+
+	// AuthorizationDecisionHandler receives authorization decisions, i.e. for audit logging.
+	AuthorizationDecisionHandler func(c echo.Context, decision AuthorizationDecision)
+)
+
+var (
+	// DefaultAuthorizationConfig is the default Authorization middleware config.
+	DefaultAuthorizationConfig = AuthorizationConfig{
+		Skipper:         DefaultSkipper,
+		DecisionHandler: logAuthorizationDecision,
+		ContextKey:      "principal",
+	}
+)
+
+// ErrPrincipalMissing is returned when PrincipalExtractor is not able to extract principal from the request.
+var ErrPrincipalMissing = errors.New("principal is missing")
+
+// Authorize returns an Authorization middleware which allows access only when all rules are satisfied by the
+// principal of the request.
+//
+// For missing principal it returns "401 - Unauthorized" error.
+// For denied access it returns "403 - Forbidden" error. The reason of denial is logged and stored as internal error
+// but it is not sent to the client.
+//
+// Example:
+//
+//	authz := middleware.Authorize(middleware.JWTPrincipalExtractor("user", "roles", "scope"), middleware.RequireRoles("admin"))
+//	e.DELETE("/users/:id", deleteUser, authz)
+func Authorize(extractor PrincipalExtractor, rules ...AuthorizationRule) echo.MiddlewareFunc {
+	c := DefaultAuthorizationConfig
+	c.PrincipalExtractor = extractor
+	c.Rules = rules
+	return AuthorizationWithConfig(c)
+}
+
+// AuthorizationWithConfig returns an Authorization middleware with config.
+// See: `Authorize()`.
+func AuthorizationWithConfig(config AuthorizationConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultAuthorizationConfig.Skipper
+	}
+	if config.PrincipalExtractor == nil {
+		panic("echo: authorization middleware requires a principal extractor")
+	}
+	if len(config.Rules) == 0 {
+		panic("echo: authorization middleware requires at least one rule")
+	}
+	if config.DecisionHandler == nil {
+		config.DecisionHandler = DefaultAuthorizationConfig.DecisionHandler
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultAuthorizationConfig.ContextKey
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			decision := AuthorizationDecision{
+				Method: c.Request().Method,
+				Path:   c.Path(),
+			}
+
+			principal, err := config.PrincipalExtractor(c)
+			if err == nil && principal == nil {
+				err = ErrPrincipalMissing
+			}
+			if err != nil {
+				decision.Reason = err.Error()
+				config.DecisionHandler(c, decision)
+				return echo.ErrUnauthorized.SetInternal(err)
+			}
+			decision.Principal = principal
+
+			for _, rule := range config.Rules {
+				if err := rule(c, principal); err != nil {
+					decision.Reason = err.Error()
+					config.DecisionHandler(c, decision)
+					return &echo.HTTPError{
+						Code:     http.StatusForbidden,
+						Message:  http.StatusText(http.StatusForbidden),
+						Internal: err,
+					}
+				}
+			}
+
+			decision.Allowed = true
+			config.DecisionHandler(c, decision)
+			c.Set(config.ContextKey, principal)
+			return next(c)
+		}
+	}
+}
+
+func logAuthorizationDecision(c echo.Context, decision AuthorizationDecision) {
+	j := log.JSON{
+		"message": "authorization decision",
+		"allowed": decision.Allowed,
+		"method":  decision.Method,
+		"path":    decision.Path,
+	}
+	if decision.Principal != nil {
+		j["principal"] = decision.Principal.ID
+	}
+	if decision.Allowed {
+		c.Logger().Debugj(j)
+		return
+	}
+	j["reason"] = decision.Reason
+	c.Logger().Warnj(j)
+}
+
+// HasRole returns true when the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	return containsString(p.Roles, role)
+}
+
+// HasScope returns true when the principal has the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return containsString(p.Scopes, scope)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRoles returns a rule which allows access when the principal has at least one of the roles.
+func RequireRoles(roles ...string) AuthorizationRule {
+	return func(c echo.Context, p *Principal) error {
+		for _, role := range roles {
+			if p.HasRole(role) {
+				return nil
+			}
+		}
+		return fmt.Errorf("principal has none of required roles: %v", strings.Join(roles, ", "))
+	}
+}
+
+// RequireScopes returns a rule which allows access when the principal has all of the scopes.
+func RequireScopes(scopes ...string) AuthorizationRule {
+	return func(c echo.Context, p *Principal) error {
+		for _, scope := range scopes {
+			if !p.HasScope(scope) {
+				return fmt.Errorf("principal is missing required scope: %v", scope)
+			}
+		}
+		return nil
+	}
+}
+
+// RequirePolicy returns a rule which allows access when the policy expression evaluates to true. The expression is
+// compiled immediately and RequirePolicy panics when it is invalid so misconfiguration is detected at startup.
+//
+// Expression syntax:
+//   - `role:<name>` is true when the principal has the role
+//   - `scope:<name>` is true when the principal has the scope
+//   - `<operand> == <operand>` and `<operand> != <operand>` compare values where operand is one of
+//     `attr:<name>` (principal attribute), `param:<name>` (path parameter), `query:<name>` (query parameter),
+//     `header:<name>` (request header), `id` (principal ID) or a quoted string literal. Comparison is false (for
+//     both `==` and `!=`) when any operand is missing
+//   - `!`, `&&`, `||` and parentheses combine expressions
+//
+// Example:
+//
+//	middleware.RequirePolicy(`role:admin || (scope:orders.write && attr:tenant == param:tenant)`)
+func RequirePolicy(expression string) AuthorizationRule {
+	policy, err := parsePolicy(expression)
+	if err != nil {
+		panic(fmt.Sprintf("echo: invalid authorization policy %q: %v", expression, err))
+	}
+	return func(c echo.Context, p *Principal) error {
+		if !policy.eval(c, p) {
+			return fmt.Errorf("policy denied access: %v", expression)
+		}
+		return nil
+	}
+}
+
+// BasicAuthPrincipalExtractor returns a PrincipalExtractor for usernames stored into context by BasicAuth middleware
+// under contextKey (see `BasicAuthConfig.ContextKey`). The lookup function resolves username to principal, i.e. loads user roles from database.
+func BasicAuthPrincipalExtractor(contextKey string, lookup func(username string, c echo.Context) (*Principal, error)) PrincipalExtractor {
+	return func(c echo.Context) (*Principal, error) {
+		username, ok := c.Get(contextKey).(string)
+		if !ok || username == "" {
+			return nil, ErrPrincipalMissing
+		}
+		return lookup(username, c)
+	}
+}
+
+// KeyAuthPrincipalExtractor returns a PrincipalExtractor for keys stored into context by KeyAuth middleware under
+// contextKey (see `KeyAuthConfig.ContextKey`). The lookup function resolves key to principal, i.e. loads scopes granted to the API key.
+func KeyAuthPrincipalExtractor(contextKey string, lookup func(key string, c echo.Context) (*Principal, error)) PrincipalExtractor {
+	return func(c echo.Context) (*Principal, error) {
+		key, ok := c.Get(contextKey).(string)
+		if !ok || key == "" {
+			return nil, ErrPrincipalMissing
+		}
+		return lookup(key, c)
+	}
+}
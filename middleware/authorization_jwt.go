@@ -0,0 +1,56 @@
+//go:build go1.15
+// +build go1.15
+
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// JWTPrincipalExtractor returns a PrincipalExtractor for tokens stored into context by JWT middleware under
+// contextKey. Principal ID is taken from the `sub` claim, roles from rolesClaim and scopes from scopesClaim. Claims
+// can be either lists of strings or space separated strings (i.e. OAuth2 `scope` claim). All claims are available
+// as principal attributes. Only tokens with `jwt.MapClaims` claims are supported.
+func JWTPrincipalExtractor(contextKey, rolesClaim, scopesClaim string) PrincipalExtractor {
+	return func(c echo.Context) (*Principal, error) {
+		token, ok := c.Get(contextKey).(*jwt.Token)
+		if !ok {
+			return nil, ErrPrincipalMissing
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, errors.New("jwt claims are not of type jwt.MapClaims")
+		}
+		p := &Principal{
+			Roles:      claimStrings(claims[rolesClaim]),
+			Scopes:     claimStrings(claims[scopesClaim]),
+			Attributes: claims,
+		}
+		if sub, ok := claims["sub"].(string); ok {
+			p.ID = sub
+		}
+		return p, nil
+	}
+}
+
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
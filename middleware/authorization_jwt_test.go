@@ -0,0 +1,42 @@
+//go:build go1.15
+// +build go1.15
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTPrincipalExtractor(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	extractor := JWTPrincipalExtractor("user", "roles", "scope")
+
+	_, err := extractor(c)
+	assert.Equal(t, ErrPrincipalMissing, err)
+
+	c.Set("user", &jwt.Token{Claims: &jwt.StandardClaims{Subject: "joe"}})
+	_, err = extractor(c)
+	assert.EqualError(t, err, "jwt claims are not of type jwt.MapClaims")
+
+	claims := jwt.MapClaims{
+		"sub":    "joe",
+		"roles":  []interface{}{"admin", "user"},
+		"scope":  "orders.read orders.write",
+		"tenant": "acme",
+	}
+	c.Set("user", &jwt.Token{Claims: claims})
+	p, err := extractor(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "joe", p.ID)
+		assert.Equal(t, []string{"admin", "user"}, p.Roles)
+		assert.Equal(t, []string{"orders.read", "orders.write"}, p.Scopes)
+		assert.Equal(t, "acme", p.Attributes["tenant"])
+	}
+}
@@ -0,0 +1,263 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// policyExpr is a compiled node of authorization policy expression.
+type policyExpr interface {
+	eval(c echo.Context, p *Principal) bool
+}
+
+type (
+	policyOr      struct{ left, right policyExpr }
+	policyAnd     struct{ left, right policyExpr }
+	policyNot     struct{ expr policyExpr }
+	policyRole    string
+	policyScope   string
+	policyCompare struct {
+		left, right policyOperand
+		equal       bool
+	}
+)
+
+// policyOperand returns value of comparison operand and whether the value exists.
+type policyOperand func(c echo.Context, p *Principal) (string, bool)
+
+func (e policyOr) eval(c echo.Context, p *Principal) bool {
+	return e.left.eval(c, p) || e.right.eval(c, p)
+}
+
+func (e policyAnd) eval(c echo.Context, p *Principal) bool {
+	return e.left.eval(c, p) && e.right.eval(c, p)
+}
+
+func (e policyNot) eval(c echo.Context, p *Principal) bool {
+	return !e.expr.eval(c, p)
+}
+
+func (e policyRole) eval(c echo.Context, p *Principal) bool {
+	return p.HasRole(string(e))
+}
+
+func (e policyScope) eval(c echo.Context, p *Principal) bool {
+	return p.HasScope(string(e))
+}
+
+// eval compares operand values. Comparison with missing operand fails for both `==` and `!=` so that i.e.
+// `attr:tenant != param:tenant` does not allow access when any of them is missing.
+func (e policyCompare) eval(c echo.Context, p *Principal) bool {
+	left, lok := e.left(c, p)
+	right, rok := e.right(c, p)
+	if !lok || !rok {
+		return false
+	}
+	return (left == right) == e.equal
+}
+
+type policyParser struct {
+	tokens []string
+	pos    int
+}
+
+func parsePolicy(expression string) (policyExpr, error) {
+	tokens, err := tokenizePolicy(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := &policyParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizePolicy splits expression into operators, parentheses, quoted literals and terms.
+func tokenizePolicy(expression string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		switch ch := expression[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, string(ch))
+			i++
+		case strings.HasPrefix(expression[i:], "&&"), strings.HasPrefix(expression[i:], "||"),
+			strings.HasPrefix(expression[i:], "=="), strings.HasPrefix(expression[i:], "!="):
+			tokens = append(tokens, expression[i:i+2])
+			i += 2
+		case ch == '!':
+			tokens = append(tokens, "!")
+			i++
+		case ch == '"' || ch == '\'':
+			end := strings.IndexByte(expression[i+1:], ch)
+			if end < 0 {
+				return nil, errors.New("unterminated string literal")
+			}
+			tokens = append(tokens, expression[i:i+end+2])
+			i += end + 2
+		default:
+			start := i
+			for i < len(expression) && !strings.ContainsRune(" \t\n\r()!&|=\"'", rune(expression[i])) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected character %q", ch)
+			}
+			tokens = append(tokens, expression[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+func (p *policyParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *policyParser) parseOr() (policyExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = policyOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseAnd() (policyExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = policyAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseUnary() (policyExpr, error) {
+	switch token := p.peek(); token {
+	case "":
+		return nil, errors.New("unexpected end of expression")
+	case "!":
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return policyNot{expr: expr}, nil
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *policyParser) parseTerm() (policyExpr, error) {
+	token := p.tokens[p.pos]
+	p.pos++
+
+	if op := p.peek(); op == "==" || op == "!=" {
+		p.pos++
+		left, err := parsePolicyOperand(token)
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) {
+			return nil, errors.New("unexpected end of expression")
+		}
+		right, err := parsePolicyOperand(p.tokens[p.pos])
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		return policyCompare{left: left, right: right, equal: op == "=="}, nil
+	}
+
+	if v := strings.TrimPrefix(token, "role:"); v != token && v != "" {
+		return policyRole(v), nil
+	}
+	if v := strings.TrimPrefix(token, "scope:"); v != token && v != "" {
+		return policyScope(v), nil
+	}
+	return nil, fmt.Errorf("invalid term %q", token)
+}
+
+func parsePolicyOperand(token string) (policyOperand, error) {
+	if token == "id" {
+		return func(c echo.Context, p *Principal) (string, bool) {
+			return p.ID, p.ID != ""
+		}, nil
+	}
+	if len(token) >= 2 && (token[0] == '"' || token[0] == '\'') {
+		literal := token[1 : len(token)-1]
+		return func(c echo.Context, p *Principal) (string, bool) {
+			return literal, true
+		}, nil
+	}
+
+	parts := strings.SplitN(token, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid operand %q", token)
+	}
+	name := parts[1]
+	switch parts[0] {
+	case "attr":
+		return func(c echo.Context, p *Principal) (string, bool) {
+			v, ok := p.Attributes[name]
+			if !ok || v == nil {
+				return "", false
+			}
+			return fmt.Sprint(v), true
+		}, nil
+	case "param":
+		return func(c echo.Context, p *Principal) (string, bool) {
+			v := c.Param(name)
+			return v, v != ""
+		}, nil
+	case "query":
+		return func(c echo.Context, p *Principal) (string, bool) {
+			v := c.QueryParam(name)
+			return v, v != ""
+		}, nil
+	case "header":
+		return func(c echo.Context, p *Principal) (string, bool) {
+			v := c.Request().Header.Get(name)
+			return v, v != ""
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid operand %q", token)
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPrincipalExtractor(p *Principal) PrincipalExtractor {
+	return func(c echo.Context) (*Principal, error) {
+		return p, nil
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	admin := &Principal{ID: "1", Roles: []string{"admin"}, Scopes: []string{"orders.read"}}
+	reader := &Principal{
+		ID:         "2",
+		Roles:      []string{"user"},
+		Scopes:     []string{"orders.read", "orders.write"},
+		Attributes: map[string]interface{}{"tenant": "acme"},
+	}
+
+	var testCases = []struct {
+		name          string
+		whenPrincipal *Principal
+		whenRules     []AuthorizationRule
+		whenURL       string
+		expectCode    int
+		expectReason  string
+	}{
+		{
+			name:          "ok, has role",
+			whenPrincipal: admin,
+			whenRules:     []AuthorizationRule{RequireRoles("editor", "admin")},
+			expectCode:    http.StatusOK,
+		},
+		{
+			name:          "nok, missing role",
+			whenPrincipal: reader,
+			whenRules:     []AuthorizationRule{RequireRoles("editor", "admin")},
+			expectCode:    http.StatusForbidden,
+			expectReason:  "principal has none of required roles: editor, admin",
+		},
+		{
+			name:          "ok, has all scopes",
+			whenPrincipal: reader,
+			whenRules:     []AuthorizationRule{RequireScopes("orders.read", "orders.write")},
+			expectCode:    http.StatusOK,
+		},
+		{
+			name:          "nok, missing scope",
+			whenPrincipal: admin,
+			whenRules:     []AuthorizationRule{RequireScopes("orders.read", "orders.write")},
+			expectCode:    http.StatusForbidden,
+			expectReason:  "principal is missing required scope: orders.write",
+		},
+		{
+			name:          "nok, all rules must allow access",
+			whenPrincipal: admin,
+			whenRules:     []AuthorizationRule{RequireRoles("admin"), RequireScopes("orders.write")},
+			expectCode:    http.StatusForbidden,
+			expectReason:  "principal is missing required scope: orders.write",
+		},
+		{
+			name:          "ok, policy matches attribute with path param",
+			whenPrincipal: reader,
+			whenRules:     []AuthorizationRule{RequirePolicy(`role:admin || (scope:orders.write && attr:tenant == param:tenant)`)},
+			whenURL:       "/tenants/acme/orders",
+			expectCode:    http.StatusOK,
+		},
+		{
+			name:          "nok, policy denies other tenant",
+			whenPrincipal: reader,
+			whenRules:     []AuthorizationRule{RequirePolicy(`role:admin || (scope:orders.write && attr:tenant == param:tenant)`)},
+			whenURL:       "/tenants/other/orders",
+			expectCode:    http.StatusForbidden,
+			expectReason:  "policy denied access: role:admin || (scope:orders.write && attr:tenant == param:tenant)",
+		},
+		{
+			name:          "nok, extractor returns no principal",
+			whenPrincipal: nil,
+			whenRules:     []AuthorizationRule{RequireRoles("admin")},
+			expectCode:    http.StatusUnauthorized,
+			expectReason:  "principal is missing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var decision AuthorizationDecision
+			var stored interface{}
+			e := echo.New()
+			e.GET("/tenants/:tenant/orders", func(c echo.Context) error {
+				stored = c.Get("principal")
+				return c.String(http.StatusOK, "ok")
+			}, AuthorizationWithConfig(AuthorizationConfig{
+				PrincipalExtractor: testPrincipalExtractor(tc.whenPrincipal),
+				Rules:              tc.whenRules,
+				DecisionHandler: func(c echo.Context, d AuthorizationDecision) {
+					decision = d
+				},
+			}))
+
+			url := tc.whenURL
+			if url == "" {
+				url = "/tenants/acme/orders"
+			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectReason, decision.Reason)
+			assert.Equal(t, tc.expectCode == http.StatusOK, decision.Allowed)
+			assert.Equal(t, http.MethodGet, decision.Method)
+			assert.Equal(t, "/tenants/:tenant/orders", decision.Path)
+			if tc.expectCode == http.StatusOK {
+				assert.Equal(t, tc.whenPrincipal, stored)
+			} else {
+				assert.NotContains(t, rec.Body.String(), "principal")
+			}
+		})
+	}
+}
+
+func TestAuthorizationWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() {
+		AuthorizationWithConfig(AuthorizationConfig{Rules: []AuthorizationRule{RequireRoles("admin")}})
+	})
+	assert.Panics(t, func() {
+		Authorize(testPrincipalExtractor(&Principal{}))
+	})
+}
+
+func TestAuthorize_extractorError(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	mw := Authorize(func(c echo.Context) (*Principal, error) {
+		return nil, errors.New("token expired")
+	}, RequireRoles("admin"))
+	err := mw(func(c echo.Context) error { return nil })(c)
+
+	he, ok := err.(*echo.HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnauthorized, he.Code)
+		assert.EqualError(t, he.Internal, "token expired")
+	}
+}
+
+func TestRequirePolicy(t *testing.T) {
+	p := &Principal{
+		ID:         "42",
+		Roles:      []string{"user"},
+		Scopes:     []string{"read"},
+		Attributes: map[string]interface{}{"tenant": "acme", "level": 3},
+	}
+
+	var testCases = []struct {
+		expression string
+		expect     bool
+	}{
+		{expression: `role:user`, expect: true},
+		{expression: `!role:user`, expect: false},
+		{expression: `role:admin || scope:read`, expect: true},
+		{expression: `role:user && scope:write`, expect: false},
+		{expression: `!(role:admin || scope:write)`, expect: true},
+		{expression: `attr:tenant == "acme"`, expect: true},
+		{expression: `attr:tenant != 'acme'`, expect: false},
+		{expression: `attr:level == "3"`, expect: true},
+		{expression: `id == param:id`, expect: true},
+		{expression: `query:tenant == attr:tenant`, expect: true},
+		{expression: `header:X-Tenant == attr:tenant`, expect: false},
+		{expression: `attr:missing == header:X-Missing`, expect: false},
+		{expression: `attr:missing != header:X-Missing`, expect: false},
+		{expression: `attr:missing != "acme"`, expect: false},
+		{expression: `attr:tenant != header:X-Missing`, expect: false},
+		{expression: `role:user && attr:tenant == "acme" || role:admin`, expect: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/?tenant=acme", nil)
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues("42")
+
+			err := RequirePolicy(tc.expression)(c, p)
+			assert.Equal(t, tc.expect, err == nil)
+		})
+	}
+}
+
+func TestRequirePolicy_invalid(t *testing.T) {
+	var expressions = []string{
+		``,
+		`role:`,
+		`admin`,
+		`role:admin ||`,
+		`(role:admin`,
+		`role:admin)`,
+		`attr:tenant ==`,
+		`attr:tenant == "acme`,
+		`attr:tenant == cookie:tenant`,
+		`role:admin & scope:read`,
+	}
+	for _, expression := range expressions {
+		t.Run(expression, func(t *testing.T) {
+			assert.Panics(t, func() {
+				RequirePolicy(expression)
+			})
+		})
+	}
+}
+
+func TestBasicAuthPrincipalExtractor(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	extractor := BasicAuthPrincipalExtractor("username", func(username string, c echo.Context) (*Principal, error) {
+		return &Principal{ID: username, Roles: []string{"admin"}}, nil
+	})
+
+	_, err := extractor(c)
+	assert.Equal(t, ErrPrincipalMissing, err)
+
+	c.Set("username", "joe")
+	p, err := extractor(c)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{ID: "joe", Roles: []string{"admin"}}, p)
+}
+
+func TestKeyAuthPrincipalExtractor(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	extractor := KeyAuthPrincipalExtractor("key", func(key string, c echo.Context) (*Principal, error) {
+		return &Principal{ID: "service", Scopes: []string{key}}, nil
+	})
+
+	_, err := extractor(c)
+	assert.Equal(t, ErrPrincipalMissing, err)
+
+	c.Set("key", "valid-key")
+	p, err := extractor(c)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{ID: "service", Scopes: []string{"valid-key"}}, p)
+}
@@ -21,6 +21,11 @@ type (
 		// Realm is a string to define realm attribute of BasicAuth.
 		// Default value "Restricted".
 		Realm string
+
+		// Context key to store username of successfully validated credentials into context. Username is not stored
+		// when empty.
+		// Optional. Default value "".
+		ContextKey string
 	}
 
 	// BasicAuthValidator defines a function to validate BasicAuth credentials.
@@ -86,6 +91,9 @@ func BasicAuthWithConfig(config BasicAuthConfig) echo.MiddlewareFunc {
 						if err != nil {
 							return err
 						} else if valid {
+							if config.ContextKey != "" {
+								c.Set(config.ContextKey, cred[:i])
+							}
 							return next(c)
 						}
 						break
@@ -32,11 +32,13 @@ func TestBasicAuth(t *testing.T) {
 	auth := basic + " " + base64.StdEncoding.EncodeToString([]byte("joe:secret"))
 	req.Header.Set(echo.HeaderAuthorization, auth)
 	assert.NoError(h(c))
+	assert.Nil(c.Get("username"))
 
 	h = BasicAuthWithConfig(BasicAuthConfig{
-		Skipper:   nil,
-		Validator: f,
-		Realm:     "someRealm",
+		Skipper:    nil,
+		Validator:  f,
+		Realm:      "someRealm",
+		ContextKey: "username",
 	})(func(c echo.Context) error {
 		return c.String(http.StatusOK, "test")
 	})
@@ -45,6 +47,7 @@ func TestBasicAuth(t *testing.T) {
 	auth = basic + " " + base64.StdEncoding.EncodeToString([]byte("joe:secret"))
 	req.Header.Set(echo.HeaderAuthorization, auth)
 	assert.NoError(h(c))
+	assert.Equal("joe", c.Get("username"))
 
 	// Case-insensitive header scheme
 	auth = strings.ToUpper(basic) + " " + base64.StdEncoding.EncodeToString([]byte("joe:secret"))
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CachePolicy defines `Cache-Control` and `Vary` headers of a route.
+type CachePolicy struct {
+	// Public allows response to be stored by shared caches.
+	Public bool
+	// Private allows response to be stored only by private (browser) caches.
+	Private bool
+	// NoCache requires caches to revalidate response before every use.
+	NoCache bool
+	// NoStore forbids caches to store response.
+	NoStore bool
+	// MustRevalidate forbids caches to use stale response without revalidation.
+	MustRevalidate bool
+	// Immutable states that response will not change while it is fresh.
+	Immutable bool
+	// MaxAge is the time response is fresh (`max-age`). Zero omits the directive.
+	MaxAge time.Duration
+	// SharedMaxAge is the time response is fresh in shared caches (`s-maxage`). Zero omits the directive.
+	SharedMaxAge time.Duration
+	// StaleWhileRevalidate is the time stale response may be used while it is revalidated in background
+	// (`stale-while-revalidate`). Zero omits the directive.
+	StaleWhileRevalidate time.Duration
+
+	// Vary lists request headers response depends on, i.e. `Accept-Language`.
+	Vary []string
+}
+
+// String returns `Cache-Control` header value of the policy.
+func (p CachePolicy) String() string {
+	var directives []string
+	add := func(enabled bool, directive string) {
+		if enabled {
+			directives = append(directives, directive)
+		}
+	}
+	addDuration := func(d time.Duration, directive string) {
+		if d > 0 {
+			directives = append(directives, directive+"="+strconv.FormatInt(int64(d/time.Second), 10))
+		}
+	}
+	add(p.Public, "public")
+	add(p.Private, "private")
+	add(p.NoCache, "no-cache")
+	add(p.NoStore, "no-store")
+	add(p.MustRevalidate, "must-revalidate")
+	addDuration(p.MaxAge, "max-age")
+	addDuration(p.SharedMaxAge, "s-maxage")
+	addDuration(p.StaleWhileRevalidate, "stale-while-revalidate")
+	add(p.Immutable, "immutable")
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl returns a middleware which declares `Cache-Control` and `Vary` headers of a route.
+//
+// `Cache-Control` header is set only for successful (2xx and 3xx) responses when handler has not set it itself so
+// that error responses are not cached. `Vary` headers are added to all responses.
+//
+// Example:
+//
+//	e.GET("/articles", listArticles, middleware.CacheControl(middleware.CachePolicy{
+//		Public: true,
+//		MaxAge: time.Minute,
+//		Vary:   []string{echo.HeaderAcceptEncoding, "Accept-Language"},
+//	}))
+func CacheControl(policy CachePolicy) echo.MiddlewareFunc {
+	cacheControl := policy.String()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			res := c.Response()
+			res.Before(func() {
+				h := res.Header()
+				for _, v := range policy.Vary {
+					h.Add(echo.HeaderVary, v)
+				}
+				if cacheControl != "" && res.Status < http.StatusBadRequest && h.Get(echo.HeaderCacheControl) == "" {
+					h.Set(echo.HeaderCacheControl, cacheControl)
+				}
+			})
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePolicy_String(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		policy CachePolicy
+		expect string
+	}{
+		{
+			name:   "empty",
+			expect: "",
+		},
+		{
+			name:   "no store",
+			policy: CachePolicy{NoStore: true},
+			expect: "no-store",
+		},
+		{
+			name:   "immutable asset",
+			policy: CachePolicy{Public: true, MaxAge: 365 * 24 * time.Hour, Immutable: true},
+			expect: "public, max-age=31536000, immutable",
+		},
+		{
+			name: "all directives",
+			policy: CachePolicy{
+				Private:              true,
+				NoCache:              true,
+				MustRevalidate:       true,
+				MaxAge:               time.Minute,
+				SharedMaxAge:         2 * time.Minute,
+				StaleWhileRevalidate: 30 * time.Second,
+			},
+			expect: "private, no-cache, must-revalidate, max-age=60, s-maxage=120, stale-while-revalidate=30",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.policy.String())
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		givenHandler       echo.HandlerFunc
+		expectCacheControl string
+		expectVary         []string
+	}{
+		{
+			name: "ok, policy applied",
+			givenHandler: func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			},
+			expectCacheControl: "public, max-age=60",
+			expectVary:         []string{"Accept-Language"},
+		},
+		{
+			name: "ok, handler overrides policy",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+				return c.String(http.StatusOK, "ok")
+			},
+			expectCacheControl: "no-store",
+			expectVary:         []string{"Accept-Language"},
+		},
+		{
+			name: "ok, errors are not cached",
+			givenHandler: func(c echo.Context) error {
+				return echo.ErrNotFound
+			},
+			expectCacheControl: "",
+			expectVary:         []string{"Accept-Language"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/", tc.givenHandler, CacheControl(CachePolicy{Public: true, MaxAge: time.Minute, Vary: []string{"Accept-Language"}}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCacheControl, rec.Header().Get(echo.HeaderCacheControl))
+			assert.Equal(t, tc.expectVary, rec.Header().Values(echo.HeaderVary))
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/base64"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -61,12 +66,44 @@ type (
 		// Indicates SameSite mode of the CSRF cookie.
 		// Optional. Default value SameSiteDefaultMode.
 		CookieSameSite http.SameSite `yaml:"cookie_same_site"`
+
+		// SignedTokenSecret enables signed double-submit mode. In this mode the token is HMAC-SHA256 signed together
+		// with the session identifier returned by SessionIDExtractor, so a token planted into the cookie by an attacker
+		// (i.e. from a sibling subdomain) is rejected and tokens become invalid when the session identifier changes.
+		// Optional. Default value none (plain double-submit mode).
+		SignedTokenSecret []byte `yaml:"-"`
+
+		// SessionIDExtractor returns identifier of the session the signed token is bound to.
+		// Required when SignedTokenSecret is set.
+		SessionIDExtractor Extractor `yaml:"-"`
+
+		// AllowedOrigins is a list of origins (i.e. "https://example.com") allowed to send unsafe requests. When set,
+		// `Origin` header (or `Referer` header when `Origin` is missing) of unsafe requests must match the request's own
+		// origin or one of the listed origins. Wildcard subdomains are supported (i.e. "https://*.example.com").
+		// Requests without both headers are left to token validation.
+		// Optional. Default value none (origin is not checked).
+		AllowedOrigins []string `yaml:"allowed_origins"`
+
+		// TrustSecFetchSite enables fast path based on `Sec-Fetch-Site` request header sent by modern browsers.
+		// Unsafe requests marked as `same-origin` or `none` (user initiated) are accepted without token validation and
+		// requests marked as `cross-site` or `same-site` are rejected unless their origin is listed in AllowedOrigins.
+		// Requests without the header are validated as usual.
+		// Optional. Default value false.
+		TrustSecFetchSite bool `yaml:"trust_sec_fetch_site"`
 	}
 )
 
 // ErrCSRFInvalid is returned when CSRF check fails
 var ErrCSRFInvalid = echo.NewHTTPError(http.StatusForbidden, "invalid csrf token")
 
+// ErrCSRFOriginInvalid is returned when origin of the request is not allowed
+var ErrCSRFOriginInvalid = echo.NewHTTPError(http.StatusForbidden, "invalid csrf origin")
+
+const (
+	headerSecFetchSite = "Sec-Fetch-Site"
+	headerReferer      = "Referer"
+)
+
 var (
 	// DefaultCSRFConfig is the default CSRF middleware config.
 	DefaultCSRFConfig = CSRFConfig{
@@ -112,6 +149,9 @@ func CSRFWithConfig(config CSRFConfig) echo.MiddlewareFunc {
 	if config.CookieSameSite == http.SameSiteNoneMode {
 		config.CookieSecure = true
 	}
+	if len(config.SignedTokenSecret) > 0 && config.SessionIDExtractor == nil {
+		panic("echo: csrf middleware requires session id extractor for signed tokens")
+	}
 
 	extractors, err := createExtractors(config.TokenLookup, "")
 	if err != nil {
@@ -124,17 +164,34 @@ func CSRFWithConfig(config CSRFConfig) echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			sessionID := ""
+			if len(config.SignedTokenSecret) > 0 {
+				id, err := config.SessionIDExtractor(c)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusForbidden, "missing csrf session").SetInternal(err)
+				}
+				sessionID = id
+			}
+
 			token := ""
-			if k, err := c.Cookie(config.CookieName); err != nil {
-				token = random.String(config.TokenLength) // Generate token
-			} else {
+			if k, err := c.Cookie(config.CookieName); err == nil && config.isTokenValid(k.Value, sessionID) {
 				token = k.Value // Reuse token
+			} else {
+				token = config.generateToken(sessionID)
 			}
 
 			switch c.Request().Method {
 			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
 			default:
-				// Validate token only for requests which are not defined as 'safe' by RFC7231
+				// Validate only requests which are not defined as 'safe' by RFC7231
+				trusted, err := config.checkOrigin(c)
+				if err != nil {
+					return err
+				}
+				if trusted {
+					break
+				}
+
 				var lastExtractorErr error
 				var lastTokenErr error
 			outer:
@@ -203,3 +260,90 @@ func CSRFWithConfig(config CSRFConfig) echo.MiddlewareFunc {
 func validateCSRFToken(token, clientToken string) bool {
 	return subtle.ConstantTimeCompare([]byte(token), []byte(clientToken)) == 1
 }
+
+// generateToken creates new random token. In signed mode the token is in form of `<random>.<signature>`.
+func (config *CSRFConfig) generateToken(sessionID string) string {
+	token := random.String(config.TokenLength)
+	if len(config.SignedTokenSecret) == 0 {
+		return token
+	}
+	return token + "." + config.signToken(token, sessionID)
+}
+
+// isTokenValid checks that token from cookie can be reused. Plain tokens are always valid while signed tokens must be
+// signed for the current session.
+func (config *CSRFConfig) isTokenValid(token, sessionID string) bool {
+	if token == "" {
+		return false
+	}
+	if len(config.SignedTokenSecret) == 0 {
+		return true
+	}
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	return validateCSRFToken(config.signToken(token[:i], sessionID), token[i+1:])
+}
+
+func (config *CSRFConfig) signToken(token, sessionID string) string {
+	mac := hmac.New(sha256.New, config.SignedTokenSecret)
+	mac.Write([]byte(sessionID))
+	mac.Write([]byte{'!'})
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkOrigin verifies origin of the unsafe request. Returned trusted is true when the request does not need token
+// validation anymore.
+func (config *CSRFConfig) checkOrigin(c echo.Context) (trusted bool, err error) {
+	req := c.Request()
+	if config.TrustSecFetchSite {
+		switch req.Header.Get(headerSecFetchSite) {
+		case "same-origin", "none":
+			return true, nil
+		case "same-site", "cross-site":
+			origin := requestOrigin(req)
+			if origin == "" || !config.isOriginAllowed(origin) {
+				return false, ErrCSRFOriginInvalid
+			}
+		}
+	}
+
+	if len(config.AllowedOrigins) == 0 {
+		return false, nil
+	}
+	origin := requestOrigin(req)
+	if origin == "" {
+		return false, nil
+	}
+	if origin == c.Scheme()+"://"+req.Host || config.isOriginAllowed(origin) {
+		return false, nil
+	}
+	return false, ErrCSRFOriginInvalid
+}
+
+func (config *CSRFConfig) isOriginAllowed(origin string) bool {
+	for _, o := range config.AllowedOrigins {
+		if strings.EqualFold(o, origin) || matchSubdomain(origin, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestOrigin returns origin of the request from `Origin` header or from `Referer` header when `Origin` is missing.
+func requestOrigin(req *http.Request) string {
+	if origin := req.Header.Get(echo.HeaderOrigin); origin != "" && origin != "null" {
+		return origin
+	}
+	referer := req.Header.Get(headerReferer)
+	if referer == "" {
+		return ""
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
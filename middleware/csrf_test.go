@@ -358,3 +358,212 @@ func TestCSRFConfig_skipper(t *testing.T) {
 		})
 	}
 }
+
+func TestCSRF_signedToken(t *testing.T) {
+	config := CSRFConfig{
+		SignedTokenSecret: []byte("secret"),
+		SessionIDExtractor: func(c echo.Context) (string, error) {
+			return c.Request().Header.Get("X-Session"), nil
+		},
+	}
+	h := CSRFWithConfig(config)(func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get("csrf").(string))
+	})
+
+	// Generate signed CSRF token for session
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Session", "session-1")
+	rec := httptest.NewRecorder()
+	assert.NoError(t, h(e.NewContext(req, rec)))
+	token := rec.Body.String()
+	assert.Contains(t, token, ".")
+
+	var testCases = []struct {
+		name        string
+		givenCookie string
+		givenToken  string
+		givenSess   string
+		expectError string
+	}{
+		{
+			name:        "ok, token signed for session",
+			givenCookie: token,
+			givenToken:  token,
+			givenSess:   "session-1",
+		},
+		{
+			name:        "nok, token signed for other session",
+			givenCookie: token,
+			givenToken:  token,
+			givenSess:   "session-2",
+			expectError: "code=403, message=invalid csrf token",
+		},
+		{
+			name:        "nok, unsigned token planted into cookie",
+			givenCookie: "planted",
+			givenToken:  "planted",
+			givenSess:   "session-1",
+			expectError: "code=403, message=invalid csrf token",
+		},
+		{
+			name:        "nok, forged signature",
+			givenCookie: "planted.c2lnbmF0dXJl",
+			givenToken:  "planted.c2lnbmF0dXJl",
+			givenSess:   "session-1",
+			expectError: "code=403, message=invalid csrf token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("X-Session", tc.givenSess)
+			req.Header.Set(echo.HeaderCookie, "_csrf="+tc.givenCookie)
+			req.Header.Set(echo.HeaderXCSRFToken, tc.givenToken)
+			rec := httptest.NewRecorder()
+
+			err := h(e.NewContext(req, rec))
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.givenToken, rec.Body.String())
+			}
+		})
+	}
+
+	assert.Panics(t, func() {
+		CSRFWithConfig(CSRFConfig{SignedTokenSecret: []byte("secret")})
+	})
+}
+
+func TestCSRF_origin(t *testing.T) {
+	var testCases = []struct {
+		name                  string
+		whenAllowedOrigins    []string
+		whenTrustSecFetchSite bool
+		givenHeaders          map[string]string
+		givenToken            bool
+		expectError           string
+	}{
+		{
+			name:               "ok, same origin with token",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenHeaders:       map[string]string{echo.HeaderOrigin: "http://example.com"},
+			givenToken:         true,
+		},
+		{
+			name:               "ok, allowed wildcard origin with token",
+			whenAllowedOrigins: []string{"https://*.example.com"},
+			givenHeaders:       map[string]string{echo.HeaderOrigin: "https://app.example.com"},
+			givenToken:         true,
+		},
+		{
+			name:               "ok, allowed origin from referer",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenHeaders:       map[string]string{"Referer": "https://app.example.com/form?id=1"},
+			givenToken:         true,
+		},
+		{
+			name:               "ok, missing origin and referer is left to token validation",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenToken:         true,
+		},
+		{
+			name:               "nok, origin not allowed",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenHeaders:       map[string]string{echo.HeaderOrigin: "https://evil.com"},
+			givenToken:         true,
+			expectError:        "code=403, message=invalid csrf origin",
+		},
+		{
+			name:               "nok, referer not allowed",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenHeaders:       map[string]string{"Referer": "https://evil.com/app.example.com"},
+			givenToken:         true,
+			expectError:        "code=403, message=invalid csrf origin",
+		},
+		{
+			name:               "nok, allowed origin without token",
+			whenAllowedOrigins: []string{"https://app.example.com"},
+			givenHeaders:       map[string]string{echo.HeaderOrigin: "https://app.example.com"},
+			expectError:        "code=400, message=missing csrf token in request header",
+		},
+		{
+			name:                  "ok, same-origin fetch without token",
+			whenTrustSecFetchSite: true,
+			givenHeaders:          map[string]string{"Sec-Fetch-Site": "same-origin"},
+		},
+		{
+			name:                  "ok, user initiated fetch without token",
+			whenTrustSecFetchSite: true,
+			givenHeaders:          map[string]string{"Sec-Fetch-Site": "none"},
+		},
+		{
+			name:                  "nok, cross-site fetch",
+			whenTrustSecFetchSite: true,
+			givenHeaders:          map[string]string{"Sec-Fetch-Site": "cross-site", echo.HeaderOrigin: "https://evil.com"},
+			givenToken:            true,
+			expectError:           "code=403, message=invalid csrf origin",
+		},
+		{
+			name:                  "ok, cross-site fetch from allowed origin with token",
+			whenAllowedOrigins:    []string{"https://app.example.com"},
+			whenTrustSecFetchSite: true,
+			givenHeaders:          map[string]string{"Sec-Fetch-Site": "cross-site", echo.HeaderOrigin: "https://app.example.com"},
+			givenToken:            true,
+		},
+		{
+			name:                  "nok, cross-site fetch from allowed origin without token",
+			whenAllowedOrigins:    []string{"https://app.example.com"},
+			whenTrustSecFetchSite: true,
+			givenHeaders:          map[string]string{"Sec-Fetch-Site": "cross-site", echo.HeaderOrigin: "https://app.example.com"},
+			expectError:           "code=400, message=missing csrf token in request header",
+		},
+		{
+			name:                  "ok, missing Sec-Fetch-Site header is validated with token",
+			whenTrustSecFetchSite: true,
+			givenToken:            true,
+		},
+		{
+			name:                  "nok, missing Sec-Fetch-Site header without token",
+			whenTrustSecFetchSite: true,
+			expectError:           "code=400, message=missing csrf token in request header",
+		},
+		{
+			name:         "ok, Sec-Fetch-Site is ignored by default",
+			givenHeaders: map[string]string{"Sec-Fetch-Site": "cross-site"},
+			givenToken:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/", nil)
+			for k, v := range tc.givenHeaders {
+				req.Header.Set(k, v)
+			}
+			if tc.givenToken {
+				req.Header.Set(echo.HeaderCookie, "_csrf=token")
+				req.Header.Set(echo.HeaderXCSRFToken, "token")
+			}
+			rec := httptest.NewRecorder()
+
+			h := CSRFWithConfig(CSRFConfig{
+				AllowedOrigins:    tc.whenAllowedOrigins,
+				TrustSecFetchSite: tc.whenTrustSecFetchSite,
+			})(func(c echo.Context) error {
+				return c.String(http.StatusOK, "test")
+			})
+
+			err := h(e.NewContext(req, rec))
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
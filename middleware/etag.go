@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ETagConfig defines the config for ETag middleware.
+	ETagConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Weak enables weak ETags (`W/"<hash>"`) instead of strong ones. Weak ETags state that responses are
+		// semantically equivalent but not necessarily byte-for-byte identical.
+		// Optional. Default value false.
+		Weak bool
+
+		// Validators returns ETag and modification time of the current state of the requested resource. It is used
+		// to evaluate `If-Match`, `If-None-Match` and `If-Unmodified-Since` preconditions of PUT, PATCH and DELETE
+		// requests before handler is executed. Empty ETag means that the resource does not exist. Zero modification
+		// time means that it is unknown.
+		// Optional. When not set preconditions of PUT, PATCH and DELETE requests are not evaluated.
+		Validators func(c echo.Context) (etag string, lastModified time.Time, err error)
+	}
+
+	bufferResponseWriter struct {
+		http.ResponseWriter
+		status int
+		buf    bytes.Buffer
+	}
+)
+
+var (
+	// DefaultETagConfig is the default ETag middleware config.
+	DefaultETagConfig = ETagConfig{
+		Skipper: DefaultSkipper,
+	}
+)
+
+// ETag returns an ETag middleware.
+//
+// ETag middleware buffers GET and HEAD responses and adds ETag header computed from the response body unless
+// handler has already set it. Conditional requests with matching `If-None-Match` or `If-Modified-Since` (compared
+// to `Last-Modified` header set by handler) are answered with "304 - Not Modified".
+//
+// For PUT, PATCH and DELETE requests it evaluates `If-Match`, `If-None-Match` and `If-Unmodified-Since` preconditions
+// against `ETagConfig.Validators` and returns "412 - Precondition Failed" error when they are not satisfied.
+//
+// Whole response is kept in memory so the middleware is not suitable for large or streamed responses.
+func ETag() echo.MiddlewareFunc {
+	return ETagWithConfig(DefaultETagConfig)
+}
+
+// ETagWithConfig returns an ETag middleware with config.
+// See: `ETag()`.
+func ETagWithConfig(config ETagConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultETagConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			switch req.Method {
+			case http.MethodGet, http.MethodHead:
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				if config.Validators != nil && hasPreconditions(req.Header) {
+					etag, lastModified, err := config.Validators(c)
+					if err != nil {
+						return err
+					}
+					if !checkPreconditions(req.Header, etag, lastModified) {
+						return echo.ErrPreconditionFailed
+					}
+				}
+				return next(c)
+			default:
+				return next(c)
+			}
+
+			res := c.Response()
+			writer := &bufferResponseWriter{ResponseWriter: res.Writer}
+			res.Writer = writer
+			err := next(c)
+			res.Writer = writer.ResponseWriter
+			if !res.Committed {
+				return err
+			}
+
+			if writer.status == http.StatusOK {
+				etag := res.Header().Get(echo.HeaderETag)
+				if etag == "" {
+					etag = computeETag(writer.buf.Bytes(), config.Weak)
+					res.Header().Set(echo.HeaderETag, etag)
+				}
+				if isNotModified(req.Header, res.Header(), etag) {
+					h := res.Header()
+					h.Del(echo.HeaderContentType)
+					h.Del(echo.HeaderContentLength)
+					res.Status = http.StatusNotModified
+					res.Size = 0
+					writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+					return err
+				}
+			}
+
+			writer.ResponseWriter.WriteHeader(writer.status)
+			if _, wErr := writer.ResponseWriter.Write(writer.buf.Bytes()); wErr != nil && err == nil {
+				err = wErr
+			}
+			return err
+		}
+	}
+}
+
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+func hasPreconditions(h http.Header) bool {
+	return h.Get(echo.HeaderIfMatch) != "" || h.Get(echo.HeaderIfNoneMatch) != "" ||
+		h.Get(echo.HeaderIfUnmodifiedSince) != ""
+}
+
+// checkPreconditions evaluates preconditions of state changing request in order defined by RFC 7232 section 6.
+func checkPreconditions(h http.Header, etag string, lastModified time.Time) bool {
+	if ifMatch := h.Get(echo.HeaderIfMatch); ifMatch != "" {
+		if !matchETag(ifMatch, etag, false) {
+			return false
+		}
+	} else if ius, err := http.ParseTime(h.Get(echo.HeaderIfUnmodifiedSince)); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(ius) {
+			return false
+		}
+	}
+	if ifNoneMatch := h.Get(echo.HeaderIfNoneMatch); ifNoneMatch != "" {
+		if matchETag(ifNoneMatch, etag, true) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNotModified(reqHeader http.Header, resHeader http.Header, etag string) bool {
+	if ifNoneMatch := reqHeader.Get(echo.HeaderIfNoneMatch); ifNoneMatch != "" {
+		return matchETag(ifNoneMatch, etag, true)
+	}
+	ims, err := http.ParseTime(reqHeader.Get(echo.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(resHeader.Get(echo.HeaderLastModified))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ims)
+}
+
+// matchETag returns true when etag matches any ETag in the list of `If-Match` or `If-None-Match` header. List `*`
+// matches any existing resource. Weak comparison ignores weakness indicator while strong comparison requires both
+// ETags to be strong.
+func matchETag(list string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *bufferResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+// Flush is no-op as the response is buffered until handler returns.
+func (w *bufferResponseWriter) Flush() {}
+
+func (w *bufferResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	lastModified := time.Date(2021, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name             string
+		givenConfig      ETagConfig
+		givenHandler     echo.HandlerFunc
+		whenHeaders      map[string]string
+		expectCode       int
+		expectBody       string
+		expectETag       string
+		expectETagRegexp string
+	}{
+		{
+			name:             "ok, strong etag is computed",
+			expectCode:       http.StatusOK,
+			expectBody:       "hello",
+			expectETagRegexp: `^"[A-Za-z0-9_-]{24}"$`,
+		},
+		{
+			name:             "ok, weak etag is computed",
+			givenConfig:      ETagConfig{Weak: true},
+			expectCode:       http.StatusOK,
+			expectBody:       "hello",
+			expectETagRegexp: `^W/"[A-Za-z0-9_-]{24}"$`,
+		},
+		{
+			name:        "ok, matching If-None-Match",
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: `"other", ` + computeETag([]byte("hello"), false)},
+			expectCode:  http.StatusNotModified,
+			expectETag:  computeETag([]byte("hello"), false),
+		},
+		{
+			name:        "ok, If-None-Match uses weak comparison",
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: "W/" + computeETag([]byte("hello"), false)},
+			expectCode:  http.StatusNotModified,
+			expectETag:  computeETag([]byte("hello"), false),
+		},
+		{
+			name:        "ok, not matching If-None-Match",
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: `"other"`},
+			expectCode:  http.StatusOK,
+			expectBody:  "hello",
+			expectETag:  computeETag([]byte("hello"), false),
+		},
+		{
+			name: "ok, handler provided etag",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderETag, `"v1"`)
+				return c.String(http.StatusOK, "hello")
+			},
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: `"v1"`},
+			expectCode:  http.StatusNotModified,
+			expectETag:  `"v1"`,
+		},
+		{
+			name: "ok, If-Modified-Since with Last-Modified",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderLastModified, lastModified.Format(http.TimeFormat))
+				return c.String(http.StatusOK, "hello")
+			},
+			whenHeaders: map[string]string{echo.HeaderIfModifiedSince: lastModified.Format(http.TimeFormat)},
+			expectCode:  http.StatusNotModified,
+			expectETag:  computeETag([]byte("hello"), false),
+		},
+		{
+			name: "ok, modified since",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderLastModified, lastModified.Format(http.TimeFormat))
+				return c.String(http.StatusOK, "hello")
+			},
+			whenHeaders: map[string]string{echo.HeaderIfModifiedSince: lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+			expectCode:  http.StatusOK,
+			expectBody:  "hello",
+			expectETag:  computeETag([]byte("hello"), false),
+		},
+		{
+			name: "ok, non 200 responses have no etag",
+			givenHandler: func(c echo.Context) error {
+				return c.String(http.StatusCreated, "created")
+			},
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: "*"},
+			expectCode:  http.StatusCreated,
+			expectBody:  "created",
+		},
+		{
+			name: "ok, handler error is handled by error handler",
+			givenHandler: func(c echo.Context) error {
+				return echo.ErrNotFound
+			},
+			expectCode: http.StatusNotFound,
+			expectBody: "{\"message\":\"Not Found\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			handler := tc.givenHandler
+			if handler == nil {
+				handler = func(c echo.Context) error {
+					return c.String(http.StatusOK, "hello")
+				}
+			}
+			e.GET("/", handler, ETagWithConfig(tc.givenConfig))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.whenHeaders {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			if tc.expectETagRegexp != "" {
+				assert.Regexp(t, tc.expectETagRegexp, rec.Header().Get(echo.HeaderETag))
+			} else {
+				assert.Equal(t, tc.expectETag, rec.Header().Get(echo.HeaderETag))
+			}
+			if tc.expectCode == http.StatusNotModified {
+				assert.Empty(t, rec.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestETag_preconditions(t *testing.T) {
+	lastModified := time.Date(2021, 10, 21, 7, 28, 0, 0, time.UTC)
+	validators := func(etag string) func(c echo.Context) (string, time.Time, error) {
+		return func(c echo.Context) (string, time.Time, error) {
+			if etag == "" {
+				return "", time.Time{}, nil
+			}
+			return etag, lastModified, nil
+		}
+	}
+
+	var testCases = []struct {
+		name          string
+		givenETag     string
+		whenMethod    string
+		whenHeaders   map[string]string
+		expectCode    int
+		expectHandled bool
+	}{
+		{
+			name:          "ok, no preconditions",
+			givenETag:     `"v1"`,
+			whenMethod:    http.MethodPut,
+			expectCode:    http.StatusOK,
+			expectHandled: true,
+		},
+		{
+			name:          "ok, If-Match matches",
+			givenETag:     `"v1"`,
+			whenMethod:    http.MethodPut,
+			whenHeaders:   map[string]string{echo.HeaderIfMatch: `"v0", "v1"`},
+			expectCode:    http.StatusOK,
+			expectHandled: true,
+		},
+		{
+			name:        "nok, If-Match does not match",
+			givenETag:   `"v2"`,
+			whenMethod:  http.MethodPatch,
+			whenHeaders: map[string]string{echo.HeaderIfMatch: `"v1"`},
+			expectCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:        "nok, If-Match uses strong comparison",
+			givenETag:   `W/"v1"`,
+			whenMethod:  http.MethodPut,
+			whenHeaders: map[string]string{echo.HeaderIfMatch: `W/"v1"`},
+			expectCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:        "nok, If-Match any for missing resource",
+			givenETag:   "",
+			whenMethod:  http.MethodDelete,
+			whenHeaders: map[string]string{echo.HeaderIfMatch: "*"},
+			expectCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:          "ok, If-None-Match any creates missing resource",
+			givenETag:     "",
+			whenMethod:    http.MethodPut,
+			whenHeaders:   map[string]string{echo.HeaderIfNoneMatch: "*"},
+			expectCode:    http.StatusOK,
+			expectHandled: true,
+		},
+		{
+			name:        "nok, If-None-Match any for existing resource",
+			givenETag:   `"v1"`,
+			whenMethod:  http.MethodPut,
+			whenHeaders: map[string]string{echo.HeaderIfNoneMatch: "*"},
+			expectCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:          "ok, not modified since",
+			givenETag:     `"v1"`,
+			whenMethod:    http.MethodDelete,
+			whenHeaders:   map[string]string{echo.HeaderIfUnmodifiedSince: lastModified.Format(http.TimeFormat)},
+			expectCode:    http.StatusOK,
+			expectHandled: true,
+		},
+		{
+			name:        "nok, modified since",
+			givenETag:   `"v1"`,
+			whenMethod:  http.MethodDelete,
+			whenHeaders: map[string]string{echo.HeaderIfUnmodifiedSince: lastModified.Add(-time.Second).Format(http.TimeFormat)},
+			expectCode:  http.StatusPreconditionFailed,
+		},
+		{
+			name:          "ok, If-Match takes precedence over If-Unmodified-Since",
+			givenETag:     `"v1"`,
+			whenMethod:    http.MethodPut,
+			whenHeaders:   map[string]string{echo.HeaderIfMatch: `"v1"`, echo.HeaderIfUnmodifiedSince: lastModified.Add(-time.Second).Format(http.TimeFormat)},
+			expectCode:    http.StatusOK,
+			expectHandled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			handled := false
+			e.Add(tc.whenMethod, "/", func(c echo.Context) error {
+				handled = true
+				return c.NoContent(http.StatusOK)
+			}, ETagWithConfig(ETagConfig{Validators: validators(tc.givenETag)}))
+
+			req := httptest.NewRequest(tc.whenMethod, "/", nil)
+			for k, v := range tc.whenHeaders {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectHandled, handled)
+		})
+	}
+}
+
+func TestETag_validatorsError(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/", nil)
+	req.Header.Set(echo.HeaderIfMatch, `"v1"`)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	mw := ETagWithConfig(ETagConfig{Validators: func(c echo.Context) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("db down")
+	}})
+	err := mw(func(c echo.Context) error { return nil })(c)
+	assert.EqualError(t, err, "db down")
+}
+
+func TestETag_withCacheControl(t *testing.T) {
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "hello")
+	}, ETag(), CacheControl(CachePolicy{Public: true, MaxAge: time.Minute, Vary: []string{"Accept-Language"}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderIfNoneMatch, computeETag([]byte("hello"), false))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get(echo.HeaderCacheControl))
+	assert.Equal(t, "Accept-Language", rec.Header().Get(echo.HeaderVary))
+}
@@ -0,0 +1,313 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	gbytes "github.com/labstack/gommon/bytes"
+)
+
+type (
+	// HMACSignatureConfig defines the config for HMACSignature middleware.
+	HMACSignatureConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Secrets used to verify signatures. Request is accepted when signature matches any of the secrets so multiple
+		// secrets can be active while secrets are rotated.
+		// Required.
+		Secrets [][]byte
+
+		// SignatureLookup is a string in the form of "<source>:<name>" or "<source>:<name>:<prefix>" that is used
+		// to extract signature from the request. Prefix is removed from the value, i.e. "sha256=" for
+		// "X-Hub-Signature-256: sha256=<signature>". When header contains multiple values any of them may match.
+		// Optional. Default value "header:X-Signature".
+		// Possible values:
+		// - "header:<name>" or "header:<name>:<prefix>"
+		// - "query:<name>"
+		SignatureLookup string
+
+		// Algorithm is the hash function used for HMAC.
+		// Optional. Default value "sha256".
+		// Possible values: "sha1", "sha256", "sha512".
+		Algorithm string
+
+		// Encoding of the signature.
+		// Optional. Default value "hex".
+		// Possible values: "hex", "base64", "base64url".
+		Encoding string
+
+		// TimestampLookup is a string in the form of "<source>:<name>" that is used to extract request timestamp (unix
+		// seconds). Requests without timestamp or with timestamp outside of TimestampTolerance are rejected to prevent
+		// replay attacks. Use SignedPayload to include timestamp in signed content.
+		// Optional. Default value "header:X-Signature-Timestamp".
+		TimestampLookup string
+
+		// DisableTimestamp disables timestamp verification for senders which do not sign timestamps (i.e. GitHub
+		// webhooks). Without timestamp there is no replay protection: a captured signed request is accepted again
+		// for as long as the secret is valid, so handlers must be idempotent or deduplicate requests (i.e. by
+		// delivery ID).
+		// Optional. Default value false.
+		DisableTimestamp bool
+
+		// TimestampTolerance is the maximum allowed difference between timestamp of the request and current time.
+		// Optional. Default value 5 minutes.
+		TimestampTolerance time.Duration
+
+		// SignedPayload returns content the signature is computed over. Timestamp is empty when DisableTimestamp is
+		// set.
+		// Optional. Default value returns body when timestamp is empty and "<timestamp>.<body>" otherwise.
+		SignedPayload func(c echo.Context, timestamp string, body []byte) []byte
+
+		// BodyLimit is the maximum size of request body that is read for verification, it can be specified as `4x` or
+		// `4xB`, where x is one of the multiple from K, M, G, T or P.
+		// Optional. Default value "1M".
+		BodyLimit string
+	}
+)
+
+var (
+	// DefaultHMACSignatureConfig is the default HMACSignature middleware config.
+	DefaultHMACSignatureConfig = HMACSignatureConfig{
+		Skipper:            DefaultSkipper,
+		SignatureLookup:    "header:X-Signature",
+		TimestampLookup:    "header:X-Signature-Timestamp",
+		Algorithm:          "sha256",
+		Encoding:           "hex",
+		TimestampTolerance: 5 * time.Minute,
+		SignedPayload:      defaultSignedPayload,
+		BodyLimit:          "1M",
+	}
+)
+
+// Errors
+var (
+	ErrSignatureMissing          = echo.NewHTTPError(http.StatusBadRequest, "missing or malformed signature")
+	ErrSignatureInvalid          = echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+	ErrSignatureTimestampInvalid = echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired signature timestamp")
+)
+
+// HMACSignature returns an HMACSignature middleware.
+//
+// HMACSignature middleware verifies HMAC signature of the request body, i.e. for webhooks. Body is read once and
+// restored so handlers can bind it as usual.
+//
+// By default signature is read from `X-Signature` header and computed over "<timestamp>.<body>" where timestamp (unix
+// seconds) is read from `X-Signature-Timestamp` header and must be within 5 minutes of current time, so captured
+// requests can not be replayed later. Set `HMACSignatureConfig.DisableTimestamp` for senders which sign only the body,
+// but note that such requests have no replay protection.
+//
+// For missing signature it returns "400 - Bad Request" error.
+// For invalid signature or timestamp it returns "401 - Unauthorized" error.
+// For body exceeding BodyLimit it returns "413 - Request Entity Too Large" error.
+func HMACSignature(secrets ...[]byte) echo.MiddlewareFunc {
+	c := DefaultHMACSignatureConfig
+	c.Secrets = secrets
+	return HMACSignatureWithConfig(c)
+}
+
+// HMACSignatureWithConfig returns an HMACSignature middleware with config.
+// See: `HMACSignature()`.
+func HMACSignatureWithConfig(config HMACSignatureConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultHMACSignatureConfig.Skipper
+	}
+	if len(config.Secrets) == 0 {
+		panic("echo: hmac-signature middleware requires secrets")
+	}
+	for _, secret := range config.Secrets {
+		if len(secret) == 0 {
+			panic("echo: hmac-signature middleware requires non-empty secrets")
+		}
+	}
+	if config.SignatureLookup == "" {
+		config.SignatureLookup = DefaultHMACSignatureConfig.SignatureLookup
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = DefaultHMACSignatureConfig.Algorithm
+	}
+	if config.Encoding == "" {
+		config.Encoding = DefaultHMACSignatureConfig.Encoding
+	}
+	if config.TimestampTolerance == 0 {
+		config.TimestampTolerance = DefaultHMACSignatureConfig.TimestampTolerance
+	}
+	if config.SignedPayload == nil {
+		config.SignedPayload = DefaultHMACSignatureConfig.SignedPayload
+	}
+	if config.BodyLimit == "" {
+		config.BodyLimit = DefaultHMACSignatureConfig.BodyLimit
+	}
+
+	hashFunc, err := signatureHash(config.Algorithm)
+	if err != nil {
+		panic(err)
+	}
+	decode, err := signatureDecoder(config.Encoding)
+	if err != nil {
+		panic(err)
+	}
+	limit, err := gbytes.Parse(config.BodyLimit)
+	if err != nil {
+		panic(fmt.Errorf("echo: invalid hmac-signature body limit=%s", config.BodyLimit))
+	}
+	signatureExtractors, err := createExtractors(config.SignatureLookup, "")
+	if err != nil {
+		panic(err)
+	}
+	if config.TimestampLookup == "" {
+		config.TimestampLookup = DefaultHMACSignatureConfig.TimestampLookup
+	}
+	var timestampExtractors []ValuesExtractor
+	if !config.DisableTimestamp {
+		timestampExtractors, err = createExtractors(config.TimestampLookup, "")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			signatures := extractSignatureValues(c, signatureExtractors, decode)
+			if len(signatures) == 0 {
+				return ErrSignatureMissing
+			}
+
+			timestamp := ""
+			if timestampExtractors != nil {
+				values := extractValues(c, timestampExtractors)
+				if len(values) == 0 {
+					return ErrSignatureTimestampInvalid
+				}
+				timestamp = values[0]
+				if !isTimestampWithinTolerance(timestamp, config.TimestampTolerance) {
+					return ErrSignatureTimestampInvalid
+				}
+			}
+
+			req := c.Request()
+			if req.ContentLength > limit {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			var body []byte
+			if req.Body != nil {
+				var err error
+				body, err = ioutil.ReadAll(io.LimitReader(req.Body, limit+1))
+				if err != nil {
+					return err
+				}
+				if int64(len(body)) > limit {
+					return echo.ErrStatusRequestEntityTooLarge
+				}
+				req.Body.Close()
+			}
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			payload := config.SignedPayload(c, timestamp, body)
+			for _, secret := range config.Secrets {
+				mac := hmac.New(hashFunc, secret)
+				mac.Write(payload)
+				expected := mac.Sum(nil)
+				for _, signature := range signatures {
+					if hmac.Equal(signature, expected) {
+						return next(c)
+					}
+				}
+			}
+			return ErrSignatureInvalid
+		}
+	}
+}
+
+func defaultSignedPayload(c echo.Context, timestamp string, body []byte) []byte {
+	if timestamp == "" {
+		return body
+	}
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+func signatureHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "sha1":
+		return sha1.New, nil
+	case "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("echo: hmac-signature middleware does not support algorithm=%s", algorithm)
+}
+
+func signatureDecoder(encoding string) (func(string) ([]byte, error), error) {
+	switch encoding {
+	case "hex":
+		return hex.DecodeString, nil
+	case "base64":
+		return base64.StdEncoding.DecodeString, nil
+	case "base64url":
+		return func(s string) ([]byte, error) {
+			// accept both padded and unpadded values
+			if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+				return b, nil
+			}
+			return base64.URLEncoding.DecodeString(s)
+		}, nil
+	}
+	return nil, fmt.Errorf("echo: hmac-signature middleware does not support encoding=%s", encoding)
+}
+
+// extractValues returns values from the first extractor that succeeds.
+func extractValues(c echo.Context, extractors []ValuesExtractor) []string {
+	for _, extractor := range extractors {
+		values, err := extractor(c)
+		if err == nil && len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+func extractSignatureValues(c echo.Context, extractors []ValuesExtractor, decode func(string) ([]byte, error)) [][]byte {
+	var signatures [][]byte
+	for _, value := range extractValues(c, extractors) {
+		signature, err := decode(value)
+		if err != nil || len(signature) == 0 {
+			continue
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures
+}
+
+func isTimestampWithinTolerance(timestamp string, tolerance time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	diff := now().Sub(time.Unix(unix, 0))
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func testHMAC(h func() hash.Hash, secret, payload string) []byte {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func TestHMACSignature(t *testing.T) {
+	body := `{"event":"created"}`
+	fixedNow := time.Unix(1600000000, 0)
+	timestamp := strconv.FormatInt(fixedNow.Unix(), 10)
+
+	var testCases = []struct {
+		name         string
+		whenConfig   func(conf *HMACSignatureConfig)
+		givenHeaders map[string]string
+		givenBody    string
+		expectErr    error
+		expectErrMsg string
+	}{
+		{
+			name: "ok, defaults",
+			givenHeaders: map[string]string{
+				"X-Signature-Timestamp": timestamp,
+				"X-Signature":           hex.EncodeToString(testHMAC(sha256.New, "secret", timestamp+"."+body)),
+			},
+		},
+		{
+			name: "ok, timestamp disabled",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.DisableTimestamp = true
+			},
+			givenHeaders: map[string]string{"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", body))},
+		},
+		{
+			name: "ok, signed with previous secret",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.DisableTimestamp = true
+				conf.Secrets = [][]byte{[]byte("new-secret"), []byte("secret")}
+			},
+			givenHeaders: map[string]string{"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", body))},
+		},
+		{
+			name: "ok, header with prefix, sha1",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.DisableTimestamp = true
+				conf.SignatureLookup = "header:X-Hub-Signature:sha1="
+				conf.Algorithm = "sha1"
+			},
+			givenHeaders: map[string]string{"X-Hub-Signature": "sha1=" + hex.EncodeToString(testHMAC(sha1.New, "secret", body))},
+		},
+		{
+			name: "ok, base64 encoding",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.DisableTimestamp = true
+				conf.Encoding = "base64"
+			},
+			givenHeaders: map[string]string{"X-Signature": base64.StdEncoding.EncodeToString(testHMAC(sha256.New, "secret", body))},
+		},
+		{
+			name: "ok, timestamp within tolerance",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.TimestampLookup = "header:X-Timestamp"
+			},
+			givenHeaders: map[string]string{
+				"X-Timestamp": timestamp,
+				"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", timestamp+"."+body)),
+			},
+		},
+		{
+			name: "ok, custom signed payload",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.TimestampLookup = "header:X-Timestamp"
+				conf.SignedPayload = func(c echo.Context, timestamp string, body []byte) []byte {
+					return []byte("v0:" + timestamp + ":" + string(body))
+				}
+			},
+			givenHeaders: map[string]string{
+				"X-Timestamp": timestamp,
+				"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", "v0:"+timestamp+":"+body)),
+			},
+		},
+		{
+			name:      "nok, missing signature",
+			expectErr: ErrSignatureMissing,
+		},
+		{
+			name:         "nok, malformed signature",
+			givenHeaders: map[string]string{"X-Signature": "not-hex"},
+			expectErr:    ErrSignatureMissing,
+		},
+		{
+			name: "nok, wrong secret",
+			givenHeaders: map[string]string{
+				"X-Signature-Timestamp": timestamp,
+				"X-Signature":           hex.EncodeToString(testHMAC(sha256.New, "other", timestamp+"."+body)),
+			},
+			expectErr: ErrSignatureInvalid,
+		},
+		{
+			name: "nok, tampered body",
+			givenHeaders: map[string]string{
+				"X-Signature-Timestamp": timestamp,
+				"X-Signature":           hex.EncodeToString(testHMAC(sha256.New, "secret", timestamp+"."+body)),
+			},
+			givenBody: `{"event":"deleted"}`,
+			expectErr: ErrSignatureInvalid,
+		},
+		{
+			name:         "nok, defaults require timestamp",
+			givenHeaders: map[string]string{"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", body))},
+			expectErr:    ErrSignatureTimestampInvalid,
+		},
+		{
+			name: "nok, missing timestamp",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.TimestampLookup = "header:X-Timestamp"
+			},
+			givenHeaders: map[string]string{"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", body))},
+			expectErr:    ErrSignatureTimestampInvalid,
+		},
+		{
+			name: "nok, replayed timestamp",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.TimestampLookup = "header:X-Timestamp"
+			},
+			givenHeaders: map[string]string{
+				"X-Timestamp": "1599999000",
+				"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", "1599999000."+body)),
+			},
+			expectErr: ErrSignatureTimestampInvalid,
+		},
+		{
+			name: "nok, body too large",
+			whenConfig: func(conf *HMACSignatureConfig) {
+				conf.DisableTimestamp = true
+				conf.BodyLimit = "10B"
+			},
+			givenHeaders: map[string]string{"X-Signature": hex.EncodeToString(testHMAC(sha256.New, "secret", body))},
+			expectErr:    echo.ErrStatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldNow := now
+			now = func() time.Time { return fixedNow }
+			defer func() { now = oldNow }()
+
+			config := HMACSignatureConfig{Secrets: [][]byte{[]byte("secret")}}
+			if tc.whenConfig != nil {
+				tc.whenConfig(&config)
+			}
+
+			givenBody := body
+			if tc.givenBody != "" {
+				givenBody = tc.givenBody
+			}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(givenBody))
+			for k, v := range tc.givenHeaders {
+				req.Header.Set(k, v)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			var handlerBody string
+			h := HMACSignatureWithConfig(config)(func(c echo.Context) error {
+				b, err := ioutil.ReadAll(c.Request().Body)
+				handlerBody = string(b)
+				return err
+			})
+
+			err := h(c)
+			if tc.expectErr != nil {
+				assert.Equal(t, tc.expectErr, err)
+				assert.Empty(t, handlerBody)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, givenBody, handlerBody)
+			}
+		})
+	}
+}
+
+func TestHMACSignatureWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() {
+		HMACSignature()
+	})
+	assert.Panics(t, func() {
+		HMACSignature([]byte{})
+	})
+	assert.Panics(t, func() {
+		HMACSignatureWithConfig(HMACSignatureConfig{Secrets: [][]byte{[]byte("secret")}, Algorithm: "md5"})
+	})
+	assert.Panics(t, func() {
+		HMACSignatureWithConfig(HMACSignatureConfig{Secrets: [][]byte{[]byte("secret")}, Encoding: "base32"})
+	})
+	assert.Panics(t, func() {
+		HMACSignatureWithConfig(HMACSignatureConfig{Secrets: [][]byte{[]byte("secret")}, SignatureLookup: "header"})
+	})
+	assert.Panics(t, func() {
+		HMACSignatureWithConfig(HMACSignatureConfig{Secrets: [][]byte{[]byte("secret")}, BodyLimit: "abc"})
+	})
+}
@@ -46,6 +46,10 @@ type (
 		// In that case you can use ErrorHandler to set a default public key auth value in the request context
 		// and continue. Some logic down the remaining execution chain needs to check that (public) key auth value then.
 		ContinueOnIgnoredError bool
+
+		// Context key to store successfully validated key into context. Key is not stored when empty.
+		// Optional. Default value "".
+		ContextKey string
 	}
 
 	// KeyAuthValidator defines a function to validate KeyAuth credentials.
@@ -134,6 +138,9 @@ func KeyAuthWithConfig(config KeyAuthConfig) echo.MiddlewareFunc {
 						continue
 					}
 					if valid {
+						if config.ContextKey != "" {
+							c.Set(config.ContextKey, key)
+						}
 						return next(c)
 					}
 					lastValidatorErr = errors.New("invalid key")
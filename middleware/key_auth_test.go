@@ -40,6 +40,24 @@ func TestKeyAuth(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.True(t, handlerCalled)
+	assert.Nil(t, c.Get("key"))
+}
+
+func TestKeyAuth_contextKey(t *testing.T) {
+	middlewareChain := KeyAuthWithConfig(KeyAuthConfig{
+		Validator:  testKeyValidator,
+		ContextKey: "key",
+	})(func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer valid-key")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	assert.NoError(t, middlewareChain(c))
+	assert.Equal(t, "valid-key", c.Get("key"))
 }
 
 func TestKeyAuthWithConfig(t *testing.T) {
@@ -0,0 +1,415 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ResponseCacheConfig defines the config for ResponseCache middleware.
+	ResponseCacheConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Store defines a store for cached responses.
+		// Optional. Default value is memory store limited to 64MB (see `NewResponseCacheMemoryStore()`).
+		Store ResponseCacheStore
+
+		// TTL is the time response is fresh when handler does not set `max-age` or `s-maxage` in `Cache-Control`
+		// header.
+		// Optional. Default value 1 minute.
+		TTL time.Duration
+
+		// StaleWhileRevalidate is the time stale response is served while it is revalidated in background when
+		// handler does not set `stale-while-revalidate` in `Cache-Control` header.
+		// Optional. Default value 0.
+		StaleWhileRevalidate time.Duration
+
+		// QueryParams are query parameters included in cache key. Other query parameters are ignored. When nil all
+		// query parameters are included.
+		// Optional.
+		QueryParams []string
+
+		// CacheRequestsWithCookies enables caching of requests with `Cookie` header. Handlers producing responses
+		// depending on cookies must set `Vary: Cookie` or `Cache-Control: private` header, otherwise response for one
+		// user is served to other users.
+		// Optional. Default value false.
+		CacheRequestsWithCookies bool
+	}
+
+	// ResponseCacheStore is the interface to be implemented by custom stores of cached responses.
+	ResponseCacheStore interface {
+		// Get returns cached response for the key.
+		Get(key string) (*ResponseCacheEntry, bool)
+		// Set stores response for the key.
+		Set(key string, entry *ResponseCacheEntry)
+		// Delete removes response for the key.
+		Delete(key string)
+	}
+
+	// ResponseCacheEntry is a cached response.
+	ResponseCacheEntry struct {
+		Status int
+		Header http.Header
+		Body   []byte
+		// Vary lists request headers the response varies on. Entry stored under primary key (method, path and
+		// query) holds only Vary and times while responses are stored under keys including values of these
+		// headers.
+		Vary []string
+		// StoredAt is time when response was stored.
+		StoredAt time.Time
+		// FreshUntil is time until response can be served without revalidation.
+		FreshUntil time.Time
+		// StaleUntil is time until stale response can be served while it is revalidated in background.
+		StaleUntil time.Time
+	}
+
+	responseCache struct {
+		config ResponseCacheConfig
+		mutex  sync.Mutex
+		// calls holds handler executions in progress by cache key
+		calls map[string]chan struct{}
+	}
+
+	discardResponseWriter struct {
+		header http.Header
+	}
+)
+
+const headerAge = "Age"
+
+var (
+	// DefaultResponseCacheConfig is the default ResponseCache middleware config.
+	DefaultResponseCacheConfig = ResponseCacheConfig{
+		Skipper: DefaultSkipper,
+		TTL:     time.Minute,
+	}
+)
+
+// ResponseCache returns a ResponseCache middleware.
+//
+// ResponseCache middleware caches successful GET and HEAD responses in process. Cache key consists of method, host
+// (without port), path, query parameters and values of request headers listed in response `Vary` header. `Cache-Control` header set by
+// handler is honoured: responses with `no-store`, `no-cache` or `private` are not cached, `s-maxage` or `max-age`
+// overrides TTL and `stale-while-revalidate` overrides StaleWhileRevalidate. Responses with `Set-Cookie` header and
+// requests with `Authorization` header are never cached. Requests with `Cookie` header are not cached unless
+// CacheRequestsWithCookies is set.
+//
+// Concurrent requests for the same missing response wait for a single handler execution. Stale responses are served
+// while single background execution of handler revalidates them.
+func ResponseCache() echo.MiddlewareFunc {
+	return ResponseCacheWithConfig(DefaultResponseCacheConfig)
+}
+
+// ResponseCacheWithConfig returns a ResponseCache middleware with config.
+// See: `ResponseCache()`.
+func ResponseCacheWithConfig(config ResponseCacheConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultResponseCacheConfig.Skipper
+	}
+	if config.Store == nil {
+		config.Store = NewResponseCacheMemoryStore(64 << 20)
+	}
+	if config.TTL == 0 {
+		config.TTL = DefaultResponseCacheConfig.TTL
+	}
+	rc := &responseCache{config: config, calls: map[string]chan struct{}{}}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if config.Skipper(c) || (req.Method != http.MethodGet && req.Method != http.MethodHead) ||
+				req.Header.Get(echo.HeaderAuthorization) != "" ||
+				(!config.CacheRequestsWithCookies && req.Header.Get(echo.HeaderCookie) != "") {
+				return next(c)
+			}
+
+			key := rc.key(req)
+			for {
+				if entry, ok := rc.lookup(key, req.Header); ok {
+					if now().After(entry.FreshUntil) {
+						rc.revalidate(c, key, next)
+					}
+					return serveCachedResponse(c, entry)
+				}
+
+				rc.mutex.Lock()
+				done, inProgress := rc.calls[key]
+				if !inProgress {
+					done = make(chan struct{})
+					rc.calls[key] = done
+				}
+				rc.mutex.Unlock()
+				if !inProgress {
+					break
+				}
+				select {
+				case <-done:
+				case <-req.Context().Done():
+					return req.Context().Err()
+				}
+				if _, ok := rc.lookup(key, req.Header); !ok {
+					// response of the other request was not cacheable or it varies on different header values
+					return rc.execute(c, key, next, false)
+				}
+			}
+			return rc.execute(c, key, next, true)
+		}
+	}
+}
+
+// key returns primary cache key for the request.
+func (rc *responseCache) key(req *http.Request) string {
+	query := req.URL.Query()
+	if rc.config.QueryParams != nil {
+		selected := url.Values{}
+		for _, name := range rc.config.QueryParams {
+			if values, ok := query[name]; ok {
+				selected[name] = values
+			}
+		}
+		query = selected
+	}
+	return req.Method + " " + responseCacheHost(req.Host) + req.URL.Path + "?" + query.Encode()
+}
+
+// responseCacheHost returns host of the request without port in lower case so responses of different hosts (see
+// `Echo#Host()`) are cached separately.
+func responseCacheHost(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i > strings.LastIndexByte(host, ']') {
+		host = host[:i]
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+func variantKey(key string, vary []string, h http.Header) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, name := range vary {
+		sb.WriteString("\n")
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(strings.Join(h.Values(name), ","))
+	}
+	return sb.String()
+}
+
+// lookup returns cached response which is fresh or can be served stale.
+func (rc *responseCache) lookup(key string, h http.Header) (*ResponseCacheEntry, bool) {
+	entry, ok := rc.config.Store.Get(key)
+	if ok && len(entry.Vary) > 0 {
+		entry, ok = rc.config.Store.Get(variantKey(key, entry.Vary, h))
+	}
+	if !ok || now().After(entry.StaleUntil) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// execute executes handler and caches its response. When leader is true the execution is registered in calls and
+// other requests for the key are waiting for it.
+func (rc *responseCache) execute(c echo.Context, key string, next echo.HandlerFunc, leader bool) error {
+	if leader {
+		defer rc.release(key)
+	}
+
+	res := c.Response()
+	// headers set by preceding middlewares (e.g. request ID) are not part of cached response
+	before := res.Header().Clone()
+	writer := &bufferResponseWriter{ResponseWriter: res.Writer}
+	res.Writer = writer
+	err := next(c)
+	res.Writer = writer.ResponseWriter
+	if !res.Committed {
+		return err
+	}
+	if err == nil {
+		rc.store(key, c.Request().Header, writer.status, headerChanges(before, res.Header()), writer.buf.Bytes())
+	}
+
+	writer.ResponseWriter.WriteHeader(writer.status)
+	if _, wErr := writer.ResponseWriter.Write(writer.buf.Bytes()); wErr != nil && err == nil {
+		err = wErr
+	}
+	return err
+}
+
+func (rc *responseCache) release(key string) {
+	rc.mutex.Lock()
+	done := rc.calls[key]
+	delete(rc.calls, key)
+	rc.mutex.Unlock()
+	close(done)
+}
+
+// revalidate executes handler in background to refresh stale response unless it is already being executed.
+func (rc *responseCache) revalidate(c echo.Context, key string, next echo.HandlerFunc) {
+	rc.mutex.Lock()
+	if _, inProgress := rc.calls[key]; inProgress {
+		rc.mutex.Unlock()
+		return
+	}
+	rc.calls[key] = make(chan struct{})
+	rc.mutex.Unlock()
+
+	// request context is cancelled when the request that triggered revalidation is finished
+	req := c.Request().Clone(context.Background())
+	writer := &bufferResponseWriter{ResponseWriter: &discardResponseWriter{header: http.Header{}}}
+	bc := c.Echo().NewContext(req, writer)
+	bc.SetPath(c.Path())
+	bc.SetParamNames(c.ParamNames()...)
+	bc.SetParamValues(c.ParamValues()...)
+	logger := c.Logger()
+
+	go func() {
+		defer rc.release(key)
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("response cache revalidation of %s panicked: %v", key, r)
+			}
+		}()
+		if err := next(bc); err != nil {
+			logger.Errorf("response cache revalidation of %s failed: %v", key, err)
+			return
+		}
+		if bc.Response().Committed {
+			rc.store(key, req.Header, writer.status, bc.Response().Header(), writer.buf.Bytes())
+		}
+	}()
+}
+
+// headerChanges returns headers which were added or changed since before.
+func headerChanges(before, after http.Header) http.Header {
+	changes := http.Header{}
+	for name, values := range after {
+		if old, ok := before[name]; ok && strings.Join(old, "\n") == strings.Join(values, "\n") {
+			continue
+		}
+		changes[name] = values
+	}
+	return changes
+}
+
+// store caches response when it is cacheable.
+func (rc *responseCache) store(key string, reqHeader http.Header, status int, header http.Header, body []byte) {
+	if status != http.StatusOK || header.Get(echo.HeaderSetCookie) != "" {
+		return
+	}
+	ttl, swr, ok := parseResponseCacheControl(header.Get(echo.HeaderCacheControl))
+	if !ok {
+		return
+	}
+	if ttl < 0 {
+		ttl = rc.config.TTL
+	}
+	if swr < 0 {
+		swr = rc.config.StaleWhileRevalidate
+	}
+
+	var vary []string
+	for _, v := range header.Values(echo.HeaderVary) {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return
+			}
+			if name != "" {
+				vary = append(vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(vary)
+
+	t := now()
+	entry := &ResponseCacheEntry{
+		Status:     status,
+		Header:     header.Clone(),
+		Body:       append([]byte(nil), body...),
+		Vary:       vary,
+		StoredAt:   t,
+		FreshUntil: t.Add(ttl),
+		StaleUntil: t.Add(ttl + swr),
+	}
+	if len(vary) == 0 {
+		rc.config.Store.Set(key, entry)
+		return
+	}
+	rc.config.Store.Set(key, &ResponseCacheEntry{
+		Vary:       vary,
+		StoredAt:   t,
+		FreshUntil: entry.FreshUntil,
+		StaleUntil: entry.StaleUntil,
+	})
+	rc.config.Store.Set(variantKey(key, vary, reqHeader), entry)
+}
+
+// parseResponseCacheControl returns freshness lifetime and stale-while-revalidate window from `Cache-Control` header.
+// Negative values mean that directive is not present. Returns false when response must not be cached.
+func parseResponseCacheControl(cacheControl string) (ttl time.Duration, swr time.Duration, cacheable bool) {
+	ttl, swr = -1, -1
+	sharedMaxAge := false
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache", "private":
+			return 0, 0, false
+		case "s-maxage":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				ttl, sharedMaxAge = time.Duration(seconds)*time.Second, true
+			}
+		case "max-age":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && !sharedMaxAge {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		case "stale-while-revalidate":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				swr = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if ttl == 0 && swr <= 0 {
+		return 0, 0, false
+	}
+	return ttl, swr, true
+}
+
+func serveCachedResponse(c echo.Context, entry *ResponseCacheEntry) error {
+	res := c.Response()
+	h := res.Header()
+	for name, values := range entry.Header {
+		h[name] = append([]string(nil), values...)
+	}
+	age := now().Sub(entry.StoredAt)
+	if age < 0 {
+		age = 0
+	}
+	h.Set(headerAge, strconv.FormatInt(int64(age/time.Second), 10))
+	res.WriteHeader(entry.Status)
+	if c.Request().Method == http.MethodHead {
+		return nil
+	}
+	_, err := res.Write(entry.Body)
+	return err
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
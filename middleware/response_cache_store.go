@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"container/list"
+	"sync"
+)
+
+type (
+	// ResponseCacheMemoryStore is the built-in store implementation for ResponseCache. It evicts least recently used
+	// responses when total size of stored responses exceeds the limit.
+	ResponseCacheMemoryStore struct {
+		mutex    sync.Mutex
+		maxBytes int64
+		size     int64
+		lru      *list.List
+		entries  map[string]*list.Element
+	}
+
+	responseCacheMemoryItem struct {
+		key   string
+		entry *ResponseCacheEntry
+		size  int64
+	}
+)
+
+// NewResponseCacheMemoryStore returns an instance of ResponseCacheMemoryStore which stores at most maxBytes of
+// responses (bodies, headers and keys). Responses larger than maxBytes are not stored.
+func NewResponseCacheMemoryStore(maxBytes int64) *ResponseCacheMemoryStore {
+	return &ResponseCacheMemoryStore{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// Get returns cached response for the key. Responses which can not be served even stale anymore are removed.
+func (s *ResponseCacheMemoryStore) Get(key string) (*ResponseCacheEntry, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	item := element.Value.(*responseCacheMemoryItem)
+	if now().After(item.entry.StaleUntil) {
+		s.remove(element)
+		return nil, false
+	}
+	s.lru.MoveToFront(element)
+	return item.entry, true
+}
+
+// Set stores response for the key and evicts least recently used responses exceeding the size limit.
+func (s *ResponseCacheMemoryStore) Set(key string, entry *ResponseCacheEntry) {
+	item := &responseCacheMemoryItem{key: key, entry: entry, size: responseCacheEntrySize(key, entry)}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	if item.size > s.maxBytes {
+		return
+	}
+	s.entries[key] = s.lru.PushFront(item)
+	s.size += item.size
+	for s.size > s.maxBytes {
+		s.remove(s.lru.Back())
+	}
+}
+
+// Delete removes response for the key.
+func (s *ResponseCacheMemoryStore) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+}
+
+// Size returns total size of stored responses in bytes.
+func (s *ResponseCacheMemoryStore) Size() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.size
+}
+
+func (s *ResponseCacheMemoryStore) remove(element *list.Element) {
+	item := s.lru.Remove(element).(*responseCacheMemoryItem)
+	delete(s.entries, item.key)
+	s.size -= item.size
+}
+
+func responseCacheEntrySize(key string, entry *ResponseCacheEntry) int64 {
+	size := int64(len(key) + len(entry.Body))
+	for name, values := range entry.Header {
+		size += int64(len(name))
+		for _, v := range values {
+			size += int64(len(v))
+		}
+	}
+	for _, name := range entry.Vary {
+		size += int64(len(name))
+	}
+	return size
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testResponseCacheEntry(body string) *ResponseCacheEntry {
+	t := now()
+	return &ResponseCacheEntry{
+		Status:     http.StatusOK,
+		Header:     http.Header{"A": []string{"b"}},
+		Body:       []byte(body),
+		StoredAt:   t,
+		FreshUntil: t.Add(time.Minute),
+		StaleUntil: t.Add(time.Minute),
+	}
+}
+
+func TestResponseCacheMemoryStore(t *testing.T) {
+	current := testResponseCacheNow(t)
+	store := NewResponseCacheMemoryStore(30)
+
+	store.Set("k1", testResponseCacheEntry("1234567890")) // 2 + 10 + 2 = 14 bytes
+	store.Set("k2", testResponseCacheEntry("1234567890"))
+	assert.Equal(t, int64(28), store.Size())
+
+	// k1 is now most recently used so k2 is evicted
+	_, ok := store.Get("k1")
+	assert.True(t, ok)
+	store.Set("k3", testResponseCacheEntry("123"))
+	_, ok = store.Get("k2")
+	assert.False(t, ok)
+	_, ok = store.Get("k1")
+	assert.True(t, ok)
+	_, ok = store.Get("k3")
+	assert.True(t, ok)
+	assert.Equal(t, int64(21), store.Size())
+
+	// replacing entry updates size
+	store.Set("k3", testResponseCacheEntry(""))
+	assert.Equal(t, int64(18), store.Size())
+
+	// too large entries are not stored
+	store.Set("large", testResponseCacheEntry(strings.Repeat("x", 31)))
+	_, ok = store.Get("large")
+	assert.False(t, ok)
+	assert.Equal(t, int64(18), store.Size())
+
+	store.Delete("k1")
+	_, ok = store.Get("k1")
+	assert.False(t, ok)
+	assert.Equal(t, int64(4), store.Size())
+
+	// expired entries are removed
+	*current = current.Add(2 * time.Minute)
+	_, ok = store.Get("k3")
+	assert.False(t, ok)
+	assert.Equal(t, int64(0), store.Size())
+}
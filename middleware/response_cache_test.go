@@ -0,0 +1,372 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func testResponseCacheNow(t *testing.T) *time.Time {
+	current := time.Date(2021, 10, 21, 7, 28, 0, 0, time.UTC)
+	oldNow := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = oldNow })
+	return &current
+}
+
+func testResponseCacheRequest(e *echo.Echo, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCache(t *testing.T) {
+	current := testResponseCacheNow(t)
+	var calls int32
+	e := echo.New()
+	e.Use(ResponseCacheWithConfig(ResponseCacheConfig{QueryParams: []string{"page"}}))
+	e.GET("/items", func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.String(http.StatusOK, "items "+c.QueryParam("page")+" #"+strconv.Itoa(int(n)))
+	})
+
+	rec := testResponseCacheRequest(e, "/items?page=1", nil)
+	assert.Equal(t, "items 1 #1", rec.Body.String())
+
+	// ignored query params are not part of the key
+	rec = testResponseCacheRequest(e, "/items?page=1&utm_source=mail", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "items 1 #1", rec.Body.String())
+	assert.Equal(t, "0", rec.Header().Get("Age"))
+
+	rec = testResponseCacheRequest(e, "/items?page=2", nil)
+	assert.Equal(t, "items 2 #2", rec.Body.String())
+
+	*current = current.Add(30 * time.Second)
+	rec = testResponseCacheRequest(e, "/items?page=1", nil)
+	assert.Equal(t, "items 1 #1", rec.Body.String())
+	assert.Equal(t, "30", rec.Header().Get("Age"))
+
+	// default TTL is 1 minute
+	*current = current.Add(31 * time.Second)
+	rec = testResponseCacheRequest(e, "/items?page=1", nil)
+	assert.Equal(t, "items 1 #3", rec.Body.String())
+
+	// requests with credentials are not cached
+	rec = testResponseCacheRequest(e, "/items?page=1", map[string]string{echo.HeaderAuthorization: "Bearer x"})
+	assert.Equal(t, "items 1 #4", rec.Body.String())
+}
+
+func TestResponseCache_handlerCacheControl(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenHandler echo.HandlerFunc
+		expectCached bool
+	}{
+		{
+			name: "ok, public max-age",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=10")
+				return c.String(http.StatusOK, "ok")
+			},
+			expectCached: true,
+		},
+		{
+			name: "nok, no-store",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+				return c.String(http.StatusOK, "ok")
+			},
+		},
+		{
+			name: "nok, private",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "private, max-age=10")
+				return c.String(http.StatusOK, "ok")
+			},
+		},
+		{
+			name: "nok, max-age=0",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderCacheControl, "max-age=0")
+				return c.String(http.StatusOK, "ok")
+			},
+		},
+		{
+			name: "nok, Set-Cookie",
+			givenHandler: func(c echo.Context) error {
+				c.SetCookie(&http.Cookie{Name: "a", Value: "b"})
+				return c.String(http.StatusOK, "ok")
+			},
+		},
+		{
+			name: "nok, Vary *",
+			givenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderVary, "*")
+				return c.String(http.StatusOK, "ok")
+			},
+		},
+		{
+			name: "nok, error status",
+			givenHandler: func(c echo.Context) error {
+				return c.String(http.StatusInternalServerError, "error")
+			},
+		},
+		{
+			name: "nok, handler error",
+			givenHandler: func(c echo.Context) error {
+				return echo.ErrNotFound
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testResponseCacheNow(t)
+			calls := 0
+			e := echo.New()
+			e.GET("/", func(c echo.Context) error {
+				calls++
+				return tc.givenHandler(c)
+			}, ResponseCache())
+
+			testResponseCacheRequest(e, "/", nil)
+			testResponseCacheRequest(e, "/", nil)
+
+			if tc.expectCached {
+				assert.Equal(t, 1, calls)
+			} else {
+				assert.Equal(t, 2, calls)
+			}
+		})
+	}
+}
+
+func TestResponseCache_maxAgeAndPrecedingHeaders(t *testing.T) {
+	current := testResponseCacheNow(t)
+	calls := 0
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderXRequestID, c.Request().Header.Get("X-Test-ID"))
+			return next(c)
+		}
+	})
+	e.GET("/", func(c echo.Context) error {
+		calls++
+		c.Response().Header().Set(echo.HeaderCacheControl, "max-age=300")
+		return c.String(http.StatusOK, "ok")
+	}, ResponseCache())
+
+	testResponseCacheRequest(e, "/", map[string]string{"X-Test-ID": "1"})
+	*current = current.Add(4 * time.Minute)
+	rec := testResponseCacheRequest(e, "/", map[string]string{"X-Test-ID": "2"})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "2", rec.Header().Get(echo.HeaderXRequestID))
+	assert.Equal(t, "max-age=300", rec.Header().Get(echo.HeaderCacheControl))
+}
+
+func TestResponseCache_vary(t *testing.T) {
+	testResponseCacheNow(t)
+	calls := 0
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		calls++
+		c.Response().Header().Set(echo.HeaderVary, "Accept-Language")
+		return c.String(http.StatusOK, c.Request().Header.Get("Accept-Language"))
+	}, ResponseCache())
+
+	rec := testResponseCacheRequest(e, "/", map[string]string{"Accept-Language": "en"})
+	assert.Equal(t, "en", rec.Body.String())
+	rec = testResponseCacheRequest(e, "/", map[string]string{"Accept-Language": "de"})
+	assert.Equal(t, "de", rec.Body.String())
+	rec = testResponseCacheRequest(e, "/", map[string]string{"Accept-Language": "en"})
+	assert.Equal(t, "en", rec.Body.String())
+	rec = testResponseCacheRequest(e, "/", map[string]string{"Accept-Language": "de"})
+	assert.Equal(t, "de", rec.Body.String())
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestResponseCache_hosts(t *testing.T) {
+	testResponseCacheNow(t)
+	calls := 0
+	e := echo.New()
+	e.Host("{tenant}.example.com").GET("/", func(c echo.Context) error {
+		calls++
+		return c.String(http.StatusOK, c.HostParam("tenant"))
+	}, ResponseCache())
+
+	request := func(host string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+	assert.Equal(t, "a", request("a.example.com"))
+	assert.Equal(t, "b", request("b.example.com"))
+	// port and case of host do not change the key
+	assert.Equal(t, "a", request("A.example.com:8080"))
+	assert.Equal(t, "b", request("b.example.com"))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestResponseCache_cookies(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig ResponseCacheConfig
+		givenVary   string
+		expectCalls int
+	}{
+		{
+			name:        "ok, requests with cookies are not cached by default",
+			expectCalls: 4,
+		},
+		{
+			name:        "ok, cached requests with cookies honour Vary: Cookie",
+			givenConfig: ResponseCacheConfig{CacheRequestsWithCookies: true},
+			givenVary:   echo.HeaderCookie,
+			expectCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testResponseCacheNow(t)
+			calls := 0
+			e := echo.New()
+			e.GET("/", func(c echo.Context) error {
+				calls++
+				if tc.givenVary != "" {
+					c.Response().Header().Set(echo.HeaderVary, tc.givenVary)
+				}
+				cookie, err := c.Cookie("user")
+				if err != nil {
+					return err
+				}
+				return c.String(http.StatusOK, "hello "+cookie.Value)
+			}, ResponseCacheWithConfig(tc.givenConfig))
+
+			for i := 0; i < 2; i++ {
+				rec := testResponseCacheRequest(e, "/", map[string]string{echo.HeaderCookie: "user=alice"})
+				assert.Equal(t, "hello alice", rec.Body.String())
+				rec = testResponseCacheRequest(e, "/", map[string]string{echo.HeaderCookie: "user=bob"})
+				assert.Equal(t, "hello bob", rec.Body.String())
+			}
+			assert.Equal(t, tc.expectCalls, calls)
+		})
+	}
+}
+
+func TestResponseCache_staleWhileRevalidate(t *testing.T) {
+	current := testResponseCacheNow(t)
+	var calls int32
+	revalidated := make(chan struct{}, 1)
+	e := echo.New()
+	e.GET("/:id", func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			defer func() { revalidated <- struct{}{} }()
+		}
+		return c.String(http.StatusOK, c.Param("id")+" #"+strconv.Itoa(int(n)))
+	}, ResponseCacheWithConfig(ResponseCacheConfig{
+		TTL:                  time.Minute,
+		StaleWhileRevalidate: time.Minute,
+	}))
+
+	rec := testResponseCacheRequest(e, "/1", nil)
+	assert.Equal(t, "1 #1", rec.Body.String())
+
+	// stale response is served and revalidated in background
+	*current = current.Add(90 * time.Second)
+	rec = testResponseCacheRequest(e, "/1", nil)
+	assert.Equal(t, "1 #1", rec.Body.String())
+	select {
+	case <-revalidated:
+	case <-time.After(time.Second):
+		t.Fatal("response was not revalidated")
+	}
+	assert.Eventually(t, func() bool {
+		return testResponseCacheRequest(e, "/1", nil).Body.String() == "1 #2"
+	}, time.Second, 10*time.Millisecond)
+
+	// response older than stale-while-revalidate window is not served
+	*current = current.Add(3 * time.Minute)
+	rec = testResponseCacheRequest(e, "/1", nil)
+	assert.Equal(t, "1 #3", rec.Body.String())
+}
+
+func TestResponseCache_collapsesConcurrentMisses(t *testing.T) {
+	testResponseCacheNow(t)
+	var calls int32
+	release := make(chan struct{})
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return c.String(http.StatusOK, "ok")
+	}, ResponseCache())
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 10)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = testResponseCacheRequest(e, "/", nil).Body.String()
+		}(i)
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		assert.Equal(t, "ok", body)
+	}
+}
+
+func TestParseResponseCacheControl(t *testing.T) {
+	var testCases = []struct {
+		cacheControl    string
+		expectTTL       time.Duration
+		expectSWR       time.Duration
+		expectCacheable bool
+	}{
+		{cacheControl: "", expectTTL: -1, expectSWR: -1, expectCacheable: true},
+		{cacheControl: "public, max-age=60", expectTTL: time.Minute, expectSWR: -1, expectCacheable: true},
+		{cacheControl: "s-maxage=120, max-age=60", expectTTL: 2 * time.Minute, expectSWR: -1, expectCacheable: true},
+		{cacheControl: "max-age=60, s-maxage=120", expectTTL: 2 * time.Minute, expectSWR: -1, expectCacheable: true},
+		{cacheControl: `max-age="60", stale-while-revalidate=30`, expectTTL: time.Minute, expectSWR: 30 * time.Second, expectCacheable: true},
+		{cacheControl: "max-age=0, stale-while-revalidate=30", expectTTL: 0, expectSWR: 30 * time.Second, expectCacheable: true},
+		{cacheControl: "max-age=0"},
+		{cacheControl: "No-Store"},
+		{cacheControl: "no-cache"},
+		{cacheControl: "private"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cacheControl, func(t *testing.T) {
+			ttl, swr, cacheable := parseResponseCacheControl(tc.cacheControl)
+			assert.Equal(t, tc.expectCacheable, cacheable)
+			if tc.expectCacheable {
+				assert.Equal(t, tc.expectTTL, ttl)
+				assert.Equal(t, tc.expectSWR, swr)
+			}
+		})
+	}
+}
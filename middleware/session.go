@@ -0,0 +1,419 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SessionsConfig defines the config for Sessions middleware.
+	SessionsConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Store persists session records between requests.
+		// Optional. Default value is a new SessionMemoryStore.
+		Store SessionStore
+
+		// Context key to store the session into context.
+		// Optional. Default value "session".
+		ContextKey string
+
+		// IdleTimeout is the duration of inactivity after which a session expires.
+		// Optional. Default value 30 minutes.
+		IdleTimeout time.Duration
+
+		// AbsoluteTimeout is the maximum lifetime of a session regardless of activity.
+		// Optional. Default value 24 hours.
+		AbsoluteTimeout time.Duration
+
+		// Name of the session cookie.
+		// Optional. Default value "_session".
+		CookieName string
+
+		// Domain of the session cookie.
+		// Optional. Default value none.
+		CookieDomain string
+
+		// Path of the session cookie.
+		// Optional. Default value "/".
+		CookiePath string
+
+		// Indicates if session cookie is secure.
+		// Optional. Default value false.
+		CookieSecure bool
+
+		// Indicates if session cookie is HTTP only.
+		// Optional. Default value true.
+		CookieHTTPOnly *bool
+
+		// Indicates SameSite mode of the session cookie.
+		// Optional. Default value http.SameSiteLaxMode.
+		CookieSameSite http.SameSite
+	}
+
+	// SessionStore is the interface to be implemented by custom session stores.
+	//
+	// The token is the value of the session cookie. Server-side stores use the session ID as token, while stores that
+	// keep the whole session on the client (see SessionCookieStore) encode the record into the token.
+	SessionStore interface {
+		// Load returns the session record referenced by token. ErrSessionNotFound is returned when the record does not
+		// exist, is invalid or has expired.
+		Load(token string) (*SessionRecord, error)
+		// Save persists the record and returns the token to be sent to the client.
+		Save(record *SessionRecord) (token string, err error)
+		// Delete removes the session record referenced by token.
+		Delete(token string) error
+	}
+
+	// SessionRecord is the persisted form of a session.
+	SessionRecord struct {
+		ID         string
+		Values     map[string]interface{}
+		Flashes    map[string][]interface{}
+		CreatedAt  time.Time
+		AccessedAt time.Time
+		// ExpiresAt is the moment after which the record must not be loaded anymore. Stores can use it to purge records.
+		ExpiresAt time.Time
+	}
+
+	// Session is the session of the current request. It is stored into context by the Sessions middleware and is safe
+	// for concurrent use.
+	Session struct {
+		mutex       sync.RWMutex
+		record      SessionRecord
+		token       string
+		isNew       bool
+		modified    bool
+		regenerated bool
+		destroyed   bool
+	}
+)
+
+// ErrSessionNotFound is returned by session stores when a session record does not exist or is no longer valid.
+var ErrSessionNotFound = errors.New("session not found")
+
+var (
+	// DefaultSessionsConfig is the default Sessions middleware config.
+	DefaultSessionsConfig = SessionsConfig{
+		Skipper:         DefaultSkipper,
+		ContextKey:      "session",
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 24 * time.Hour,
+		CookieName:      "_session",
+		CookiePath:      "/",
+		CookieSameSite:  http.SameSiteLaxMode,
+	}
+)
+
+// Sessions returns a Sessions middleware which keeps sessions in memory.
+//
+// The session of the current request is available with `GetSession(c)`.
+func Sessions() echo.MiddlewareFunc {
+	return SessionsWithConfig(DefaultSessionsConfig)
+}
+
+// SessionsWithConfig returns a Sessions middleware with config.
+// See `Sessions()`.
+func SessionsWithConfig(config SessionsConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultSessionsConfig.Skipper
+	}
+	if config.Store == nil {
+		config.Store = NewSessionMemoryStore()
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultSessionsConfig.ContextKey
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultSessionsConfig.IdleTimeout
+	}
+	if config.AbsoluteTimeout == 0 {
+		config.AbsoluteTimeout = DefaultSessionsConfig.AbsoluteTimeout
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionsConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultSessionsConfig.CookiePath
+	}
+	if config.CookieHTTPOnly == nil {
+		httpOnly := true
+		config.CookieHTTPOnly = &httpOnly
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultSessionsConfig.CookieSameSite
+	}
+	if config.CookieSameSite == http.SameSiteNoneMode {
+		config.CookieSecure = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			sess, err := config.load(c)
+			if err != nil {
+				return err
+			}
+			c.Set(config.ContextKey, sess)
+
+			// Session cookie has to be written before response headers are sent.
+			saved := false
+			var saveErr error
+			save := func() {
+				if saved {
+					return
+				}
+				saved = true
+				saveErr = config.save(c, sess)
+			}
+			c.Response().Before(func() {
+				save()
+				if saveErr != nil {
+					c.Logger().Errorf("session: failed to save session: %v", saveErr)
+				}
+			})
+
+			err = next(c)
+			if !c.Response().Committed {
+				save()
+				if err == nil {
+					err = saveErr
+				}
+			}
+			return err
+		}
+	}
+}
+
+// GetSession returns the session stored into context by the Sessions middleware with the default context key.
+// It returns nil when the middleware has not been executed for the request. Use `GetSessionWithKey` when the
+// middleware is configured with custom `SessionsConfig.ContextKey`.
+func GetSession(c echo.Context) *Session {
+	return GetSessionWithKey(c, DefaultSessionsConfig.ContextKey)
+}
+
+// GetSessionWithKey returns the session stored into context by the Sessions middleware with the given context key.
+// It returns nil when the middleware has not been executed for the request.
+func GetSessionWithKey(c echo.Context, contextKey string) *Session {
+	sess, _ := c.Get(contextKey).(*Session)
+	return sess
+}
+
+func (config *SessionsConfig) load(c echo.Context) (*Session, error) {
+	cookie, err := c.Cookie(config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return newSession()
+	}
+
+	record, err := config.Store.Load(cookie.Value)
+	if err == ErrSessionNotFound {
+		return newSession()
+	} else if err != nil {
+		return nil, err
+	}
+
+	t := now()
+	if t.After(record.ExpiresAt) ||
+		t.Sub(record.AccessedAt) > config.IdleTimeout ||
+		t.Sub(record.CreatedAt) > config.AbsoluteTimeout {
+		if err := config.Store.Delete(cookie.Value); err != nil {
+			return nil, err
+		}
+		return newSession()
+	}
+	if record.Values == nil {
+		record.Values = map[string]interface{}{}
+	}
+	if record.Flashes == nil {
+		record.Flashes = map[string][]interface{}{}
+	}
+	return &Session{record: *record, token: cookie.Value}, nil
+}
+
+func (config *SessionsConfig) save(c echo.Context, sess *Session) error {
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
+
+	if sess.destroyed {
+		if sess.token != "" {
+			if err := config.Store.Delete(sess.token); err != nil {
+				return err
+			}
+		}
+		cookie := config.newCookie("")
+		cookie.MaxAge = -1
+		cookie.Expires = time.Unix(0, 0)
+		c.SetCookie(cookie)
+		return nil
+	}
+	// Do not create sessions for clients that never stored anything into them.
+	if sess.isNew && !sess.modified {
+		return nil
+	}
+
+	if sess.regenerated && sess.token != "" {
+		if err := config.Store.Delete(sess.token); err != nil {
+			return err
+		}
+	}
+
+	t := now()
+	sess.record.AccessedAt = t
+	sess.record.ExpiresAt = t.Add(config.IdleTimeout)
+	if absolute := sess.record.CreatedAt.Add(config.AbsoluteTimeout); absolute.Before(sess.record.ExpiresAt) {
+		sess.record.ExpiresAt = absolute
+	}
+
+	token, err := config.Store.Save(&sess.record)
+	if err != nil {
+		return err
+	}
+	sess.token = token
+
+	cookie := config.newCookie(token)
+	cookie.Expires = sess.record.ExpiresAt
+	c.SetCookie(cookie)
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderCookie)
+	return nil
+}
+
+func (config *SessionsConfig) newCookie(value string) *http.Cookie {
+	cookie := new(http.Cookie)
+	cookie.Name = config.CookieName
+	cookie.Value = value
+	cookie.Path = config.CookiePath
+	if config.CookieDomain != "" {
+		cookie.Domain = config.CookieDomain
+	}
+	if config.CookieSameSite != http.SameSiteDefaultMode {
+		cookie.SameSite = config.CookieSameSite
+	}
+	cookie.Secure = config.CookieSecure
+	cookie.HttpOnly = *config.CookieHTTPOnly
+	return cookie
+}
+
+func newSession() (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	t := now()
+	return &Session{
+		record: SessionRecord{
+			ID:         id,
+			Values:     map[string]interface{}{},
+			Flashes:    map[string][]interface{}{},
+			CreatedAt:  t,
+			AccessedAt: t,
+		},
+		isNew: true,
+	}, nil
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ID returns the session identifier.
+func (s *Session) ID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.record.ID
+}
+
+// IsNew returns true when the session was created by the current request.
+func (s *Session) IsNew() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.isNew
+}
+
+// CreatedAt returns the time when the session was created.
+func (s *Session) CreatedAt() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.record.CreatedAt
+}
+
+// Get returns the value stored in the session for the key.
+func (s *Session) Get(key string) interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.record.Values[key]
+}
+
+// Set stores the value in the session for the key.
+func (s *Session) Set(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.record.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value for the key from the session.
+func (s *Session) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.record.Values, key)
+	s.modified = true
+}
+
+// AddFlash adds a flash message for the key. Flash messages are kept until they are read with `Flashes`.
+func (s *Session) AddFlash(key string, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.record.Flashes[key] = append(s.record.Flashes[key], value)
+	s.modified = true
+}
+
+// Flashes returns and removes all flash messages for the key.
+func (s *Session) Flashes(key string) []interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	flashes, ok := s.record.Flashes[key]
+	if !ok {
+		return nil
+	}
+	delete(s.record.Flashes, key)
+	s.modified = true
+	return flashes
+}
+
+// Regenerate assigns a new identifier to the session while keeping its values. The record stored under the old
+// identifier is deleted when the session is saved. Call it whenever the privilege level changes (i.e. login or logout)
+// to prevent session fixation.
+func (s *Session) Regenerate() error {
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.record.ID = id
+	s.regenerated = true
+	s.modified = true
+	return nil
+}
+
+// Destroy removes the session from the store and expires the session cookie.
+func (s *Session) Destroy() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.destroyed = true
+}
@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SessionMemoryStore is the built-in in-memory store implementation for Sessions middleware. Sessions are lost
+	// when the process exits and are not shared between multiple instances of the application.
+	SessionMemoryStore struct {
+		mutex       sync.Mutex
+		records     map[string]SessionRecord
+		lastCleanup time.Time
+	}
+
+	// SessionFileStore is a store implementation for Sessions middleware which keeps every session in a separate file
+	// in a directory. Values are encoded with `encoding/gob` so custom types stored in sessions must be registered
+	// with `gob.Register`.
+	SessionFileStore struct {
+		mutex sync.RWMutex
+		dir   string
+	}
+
+	// SessionCookieStore is a store implementation for Sessions middleware which keeps the whole session in the
+	// session cookie. The record is signed or encrypted with keys of `echo.CookieKeyRing`. Values are encoded with
+	// `encoding/gob` so custom types stored in sessions must be registered with `gob.Register`.
+	SessionCookieStore struct {
+		keyRing *echo.CookieKeyRing
+		encrypt bool
+	}
+)
+
+const (
+	// sessionCookieMaxLength is the maximum length of a cookie value most browsers are able to store.
+	sessionCookieMaxLength = 4096
+	sessionFilePrefix      = "session_"
+	sessionCleanupInterval = time.Minute
+	// sessionCookieKeyName binds signed and encrypted session records to their purpose.
+	sessionCookieKeyName = "session"
+)
+
+var (
+	errSessionCookieTooLong = errors.New("session: encoded session exceeds maximum cookie length")
+	errSessionInvalidKey    = errors.New("session: encryption key must be 16, 24 or 32 bytes long")
+)
+
+// NewSessionMemoryStore returns an instance of SessionMemoryStore.
+func NewSessionMemoryStore() *SessionMemoryStore {
+	return &SessionMemoryStore{
+		records:     map[string]SessionRecord{},
+		lastCleanup: now(),
+	}
+}
+
+// Load implements SessionStore.Load
+func (store *SessionMemoryStore) Load(token string) (*SessionRecord, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	record, ok := store.records[token]
+	if !ok || now().After(record.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	record = copySessionRecord(record)
+	return &record, nil
+}
+
+// Save implements SessionStore.Save
+func (store *SessionMemoryStore) Save(record *SessionRecord) (string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.records[record.ID] = copySessionRecord(*record)
+	if now().Sub(store.lastCleanup) > sessionCleanupInterval {
+		store.cleanupExpired()
+	}
+	return record.ID, nil
+}
+
+// Delete implements SessionStore.Delete
+func (store *SessionMemoryStore) Delete(token string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	delete(store.records, token)
+	return nil
+}
+
+func (store *SessionMemoryStore) cleanupExpired() {
+	t := now()
+	for id, record := range store.records {
+		if t.After(record.ExpiresAt) {
+			delete(store.records, id)
+		}
+	}
+	store.lastCleanup = t
+}
+
+// copySessionRecord copies value and flash maps so concurrent requests of same session do not share them.
+func copySessionRecord(record SessionRecord) SessionRecord {
+	values := make(map[string]interface{}, len(record.Values))
+	for k, v := range record.Values {
+		values[k] = v
+	}
+	flashes := make(map[string][]interface{}, len(record.Flashes))
+	for k, v := range record.Flashes {
+		flashes[k] = append([]interface{}{}, v...)
+	}
+	record.Values = values
+	record.Flashes = flashes
+	return record
+}
+
+// NewSessionFileStore returns an instance of SessionFileStore keeping session files in dir. The directory is created
+// when it does not exist.
+func NewSessionFileStore(dir string) (*SessionFileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &SessionFileStore{dir: dir}, nil
+}
+
+// Load implements SessionStore.Load
+func (store *SessionFileStore) Load(token string) (*SessionRecord, error) {
+	name, ok := store.filename(token)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	store.mutex.RLock()
+	b, err := ioutil.ReadFile(name)
+	store.mutex.RUnlock()
+	if os.IsNotExist(err) {
+		return nil, ErrSessionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	record := new(SessionRecord)
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(record); err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if now().After(record.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return record, nil
+}
+
+// Save implements SessionStore.Save
+func (store *SessionFileStore) Save(record *SessionRecord) (string, error) {
+	name, ok := store.filename(record.ID)
+	if !ok {
+		return "", ErrSessionNotFound
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(record); err != nil {
+		return "", err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	// Write to temporary file first so concurrent readers never see partially written session.
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		return "", err
+	}
+	return record.ID, nil
+}
+
+// Delete implements SessionStore.Delete
+func (store *SessionFileStore) Delete(token string) error {
+	name, ok := store.filename(token)
+	if !ok {
+		return nil
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Cleanup removes all expired session files from the store directory.
+func (store *SessionFileStore) Cleanup() error {
+	files, err := filepath.Glob(filepath.Join(store.dir, sessionFilePrefix+"*"))
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		token := filepath.Base(name)[len(sessionFilePrefix):]
+		if _, err := store.Load(token); err == ErrSessionNotFound {
+			if err := store.Delete(token); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// filename returns path to session file. Token is only accepted when it is a session ID generated by the middleware
+// so it can not be used to traverse out of store directory.
+func (store *SessionFileStore) filename(token string) (string, bool) {
+	if len(token) != 64 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(token); err != nil {
+		return "", false
+	}
+	return filepath.Join(store.dir, sessionFilePrefix+token), true
+}
+
+// NewSessionCookieStore returns an instance of SessionCookieStore. The hashKey is used to sign the session and is
+// required, 32 or 64 bytes long keys are recommended. The blockKey enables encryption of the session and must be 16, 24
+// or 32 bytes long. Encryption is disabled when blockKey is nil. Use `NewSessionCookieStoreWithKeyRing` to be able to
+// rotate keys.
+func NewSessionCookieStore(hashKey, blockKey []byte) (*SessionCookieStore, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("session: hash key is required")
+	}
+	if blockKey != nil && len(blockKey) != 16 && len(blockKey) != 24 && len(blockKey) != 32 {
+		return nil, errSessionInvalidKey
+	}
+	// key ring derives its signing and encryption keys from single key so both keys are mixed into it
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write(blockKey)
+	keyRing, err := echo.NewCookieKeyRing(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return NewSessionCookieStoreWithKeyRing(keyRing, blockKey != nil), nil
+}
+
+// NewSessionCookieStoreWithKeyRing returns an instance of SessionCookieStore using keys of keyRing. Session is signed
+// and, when encrypt is set, also encrypted so the client is not able to read values stored in the session.
+func NewSessionCookieStoreWithKeyRing(keyRing *echo.CookieKeyRing, encrypt bool) *SessionCookieStore {
+	if keyRing == nil {
+		panic("echo: session cookie store requires a cookie key ring")
+	}
+	return &SessionCookieStore{keyRing: keyRing, encrypt: encrypt}
+}
+
+// Load implements SessionStore.Load
+func (store *SessionCookieStore) Load(token string) (*SessionRecord, error) {
+	var payload string
+	var err error
+	if store.encrypt {
+		payload, err = store.keyRing.Decrypt(sessionCookieKeyName, token)
+	} else {
+		payload, err = store.keyRing.Verify(sessionCookieKeyName, token)
+	}
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	record := new(SessionRecord)
+	if err := gob.NewDecoder(strings.NewReader(payload)).Decode(record); err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if now().After(record.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return record, nil
+}
+
+// Save implements SessionStore.Save
+func (store *SessionCookieStore) Save(record *SessionRecord) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(record); err != nil {
+		return "", err
+	}
+
+	// Expiration is checked against record itself so the key ring does not need to track it.
+	var token string
+	if store.encrypt {
+		var err error
+		if token, err = store.keyRing.Encrypt(sessionCookieKeyName, buf.String(), time.Time{}); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if token, err = store.keyRing.Sign(sessionCookieKeyName, buf.String(), time.Time{}); err != nil {
+			return "", err
+		}
+	}
+	if len(token) > sessionCookieMaxLength {
+		return "", errSessionCookieTooLong
+	}
+	return token, nil
+}
+
+// Delete implements SessionStore.Delete. Session stored in cookie is deleted by expiring the cookie so there is
+// nothing to do on server side.
+func (store *SessionCookieStore) Delete(token string) error {
+	return nil
+}
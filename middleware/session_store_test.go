@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSessionRecord(expiresIn time.Duration) *SessionRecord {
+	t := now()
+	return &SessionRecord{
+		ID:         strings.Repeat("ab", 32),
+		Values:     map[string]interface{}{"user": "jon", "age": 42},
+		Flashes:    map[string][]interface{}{"info": {"saved"}},
+		CreatedAt:  t,
+		AccessedAt: t,
+		ExpiresAt:  t.Add(expiresIn),
+	}
+}
+
+func TestSessionStores(t *testing.T) {
+	fileStore, err := NewSessionFileStore(t.TempDir())
+	assert.NoError(t, err)
+	keyRing, err := echo.NewCookieKeyRing([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+	signedStore := NewSessionCookieStoreWithKeyRing(keyRing, false)
+	encryptedStore := NewSessionCookieStoreWithKeyRing(keyRing, true)
+
+	var stores = map[string]SessionStore{
+		"memory":           NewSessionMemoryStore(),
+		"file":             fileStore,
+		"cookie signed":    signedStore,
+		"cookie encrypted": encryptedStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			record := testSessionRecord(time.Minute)
+			token, err := store.Save(record)
+			assert.NoError(t, err)
+
+			loaded, err := store.Load(token)
+			if assert.NoError(t, err) {
+				assert.Equal(t, record.ID, loaded.ID)
+				assert.Equal(t, record.Values, loaded.Values)
+				assert.Equal(t, record.Flashes, loaded.Flashes)
+				assert.True(t, record.ExpiresAt.Equal(loaded.ExpiresAt))
+			}
+
+			expired := testSessionRecord(-time.Minute)
+			token, err = store.Save(expired)
+			assert.NoError(t, err)
+			_, err = store.Load(token)
+			assert.Equal(t, ErrSessionNotFound, err)
+
+			_, err = store.Load("unknown")
+			assert.Equal(t, ErrSessionNotFound, err)
+		})
+	}
+}
+
+func TestSessionMemoryStore_isolatesRecords(t *testing.T) {
+	store := NewSessionMemoryStore()
+	record := testSessionRecord(time.Minute)
+	token, err := store.Save(record)
+	assert.NoError(t, err)
+
+	record.Values["user"] = "changed"
+	loaded, err := store.Load(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", loaded.Values["user"])
+
+	loaded.Values["user"] = "changed"
+	loaded, err = store.Load(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", loaded.Values["user"])
+
+	assert.NoError(t, store.Delete(token))
+	_, err = store.Load(token)
+	assert.Equal(t, ErrSessionNotFound, err)
+}
+
+func TestSessionFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSessionFileStore(dir)
+	assert.NoError(t, err)
+
+	_, err = store.Load("../../etc/passwd")
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	expired := testSessionRecord(-time.Minute)
+	_, err = store.Save(expired)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, sessionFilePrefix+expired.ID))
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Cleanup())
+	_, err = os.Stat(filepath.Join(dir, sessionFilePrefix+expired.ID))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	assert.Panics(t, func() {
+		NewSessionCookieStoreWithKeyRing(nil, false)
+	})
+
+	keyRing, err := echo.NewCookieKeyRing([]byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	store := NewSessionCookieStoreWithKeyRing(keyRing, true)
+	token, err := store.Save(testSessionRecord(time.Minute))
+	assert.NoError(t, err)
+
+	tampered := []byte(token)
+	tampered[10] ^= 1
+	_, err = store.Load(string(tampered))
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	otherKeyRing, err := echo.NewCookieKeyRing([]byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	_, err = NewSessionCookieStoreWithKeyRing(otherKeyRing, true).Load(token)
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	// signed and encrypted tokens are not interchangeable
+	_, err = NewSessionCookieStoreWithKeyRing(keyRing, false).Load(token)
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	// sessions issued with previous key are still valid after rotation
+	assert.NoError(t, keyRing.Rotate([]byte("fedcba9876543210"), 2))
+	_, err = store.Load(token)
+	assert.NoError(t, err)
+
+	large := testSessionRecord(time.Minute)
+	large.Values["blob"] = strings.Repeat("x", sessionCookieMaxLength)
+	_, err = store.Save(large)
+	assert.Equal(t, errSessionCookieTooLong, err)
+}
+
+func TestNewSessionCookieStore(t *testing.T) {
+	_, err := NewSessionCookieStore(nil, nil)
+	assert.EqualError(t, err, "session: hash key is required")
+
+	_, err = NewSessionCookieStore([]byte("secret"), []byte("short"))
+	assert.Equal(t, errSessionInvalidKey, err)
+
+	store, err := NewSessionCookieStore([]byte("secret"), []byte("0123456789abcdef"))
+	assert.NoError(t, err)
+	assert.True(t, store.encrypt)
+	token, err := store.Save(testSessionRecord(time.Minute))
+	assert.NoError(t, err)
+	_, err = store.Load(token)
+	assert.NoError(t, err)
+
+	// both keys are used to derive the key ring
+	otherStore, err := NewSessionCookieStore([]byte("secret"), []byte("fedcba9876543210"))
+	assert.NoError(t, err)
+	_, err = otherStore.Load(token)
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	signedStore, err := NewSessionCookieStore([]byte("secret"), nil)
+	assert.NoError(t, err)
+	assert.False(t, signedStore.encrypt)
+	_, err = signedStore.Load(token)
+	assert.Equal(t, ErrSessionNotFound, err)
+}
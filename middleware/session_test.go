@@ -0,0 +1,265 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func sessionCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func serveSession(e *echo.Echo, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSessions(t *testing.T) {
+	e := echo.New()
+	e.Use(Sessions())
+	e.GET("/", func(c echo.Context) error {
+		sess := GetSession(c)
+		if c.QueryParam("set") != "" {
+			sess.Set("user", c.QueryParam("set"))
+		}
+		user, _ := sess.Get("user").(string)
+		return c.String(http.StatusOK, user)
+	})
+
+	// session is not created when nothing is stored in it
+	rec := serveSession(e, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, sessionCookie(rec, "_session"))
+
+	req := httptest.NewRequest(http.MethodGet, "/?set=jon", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	cookie := sessionCookie(rec, "_session")
+	if assert.NotNil(t, cookie) {
+		assert.Len(t, cookie.Value, 64)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, "/", cookie.Path)
+		assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	}
+
+	rec = serveSession(e, cookie)
+	assert.Equal(t, "jon", rec.Body.String())
+}
+
+func TestSessions_flashes(t *testing.T) {
+	e := echo.New()
+	e.Use(Sessions())
+	e.GET("/", func(c echo.Context) error {
+		sess := GetSession(c)
+		if sess.IsNew() {
+			sess.AddFlash("info", "saved")
+			sess.AddFlash("info", "twice")
+			return c.NoContent(http.StatusOK)
+		}
+		return c.JSON(http.StatusOK, sess.Flashes("info"))
+	})
+
+	cookie := sessionCookie(serveSession(e, nil), "_session")
+	assert.NotNil(t, cookie)
+
+	rec := serveSession(e, cookie)
+	assert.Equal(t, `["saved","twice"]`+"\n", rec.Body.String())
+
+	rec = serveSession(e, cookie)
+	assert.Equal(t, "null\n", rec.Body.String())
+}
+
+func TestSessions_regenerate(t *testing.T) {
+	store := NewSessionMemoryStore()
+	e := echo.New()
+	e.Use(SessionsWithConfig(SessionsConfig{Store: store}))
+	e.GET("/", func(c echo.Context) error {
+		sess := GetSession(c)
+		if sess.IsNew() {
+			sess.Set("role", "guest")
+			return c.NoContent(http.StatusOK)
+		}
+		if err := sess.Regenerate(); err != nil {
+			return err
+		}
+		sess.Set("role", "admin")
+		return c.String(http.StatusOK, sess.ID())
+	})
+
+	oldCookie := sessionCookie(serveSession(e, nil), "_session")
+	assert.NotNil(t, oldCookie)
+
+	rec := serveSession(e, oldCookie)
+	newCookie := sessionCookie(rec, "_session")
+	if assert.NotNil(t, newCookie) {
+		assert.NotEqual(t, oldCookie.Value, newCookie.Value)
+		assert.Equal(t, newCookie.Value, rec.Body.String())
+	}
+
+	_, err := store.Load(oldCookie.Value)
+	assert.Equal(t, ErrSessionNotFound, err)
+	record, err := store.Load(newCookie.Value)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "admin", record.Values["role"])
+	}
+}
+
+func TestSessions_destroy(t *testing.T) {
+	store := NewSessionMemoryStore()
+	e := echo.New()
+	e.Use(SessionsWithConfig(SessionsConfig{Store: store}))
+	e.GET("/", func(c echo.Context) error {
+		sess := GetSession(c)
+		if sess.IsNew() {
+			sess.Set("user", "jon")
+		} else {
+			sess.Destroy()
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	cookie := sessionCookie(serveSession(e, nil), "_session")
+	assert.NotNil(t, cookie)
+
+	rec := serveSession(e, cookie)
+	expired := sessionCookie(rec, "_session")
+	if assert.NotNil(t, expired) {
+		assert.Equal(t, "", expired.Value)
+		assert.Equal(t, -1, expired.MaxAge)
+	}
+	_, err := store.Load(cookie.Value)
+	assert.Equal(t, ErrSessionNotFound, err)
+}
+
+func TestSessions_expiry(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenElapsed []time.Duration
+		expectUser  string
+	}{
+		{
+			name:        "ok, session is used within idle timeout",
+			whenElapsed: []time.Duration{5 * time.Minute},
+			expectUser:  "jon",
+		},
+		{
+			name:        "ok, activity extends idle timeout",
+			whenElapsed: []time.Duration{8 * time.Minute, 8 * time.Minute},
+			expectUser:  "jon",
+		},
+		{
+			name:        "nok, idle timeout exceeded",
+			whenElapsed: []time.Duration{11 * time.Minute},
+			expectUser:  "",
+		},
+		{
+			name:        "nok, absolute timeout exceeded despite activity",
+			whenElapsed: []time.Duration{9 * time.Minute, 9 * time.Minute, 9 * time.Minute, 9 * time.Minute},
+			expectUser:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			now = func() time.Time { return clock }
+			defer func() { now = time.Now }()
+
+			e := echo.New()
+			e.Use(SessionsWithConfig(SessionsConfig{
+				IdleTimeout:     10 * time.Minute,
+				AbsoluteTimeout: 30 * time.Minute,
+			}))
+			e.GET("/", func(c echo.Context) error {
+				sess := GetSession(c)
+				if sess.IsNew() && c.QueryParam("login") != "" {
+					sess.Set("user", "jon")
+				}
+				user, _ := sess.Get("user").(string)
+				return c.String(http.StatusOK, user)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/?login=1", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			cookie := sessionCookie(rec, "_session")
+
+			for _, elapsed := range tc.whenElapsed {
+				clock = clock.Add(elapsed)
+				rec = serveSession(e, cookie)
+				if c := sessionCookie(rec, "_session"); c != nil {
+					cookie = c
+				}
+			}
+			assert.Equal(t, tc.expectUser, rec.Body.String())
+		})
+	}
+}
+
+type failingSessionStore struct {
+	*SessionMemoryStore
+}
+
+func (s failingSessionStore) Save(record *SessionRecord) (string, error) {
+	return "", errors.New("store is down")
+}
+
+func TestSessions_saveError(t *testing.T) {
+	e := echo.New()
+	e.Use(SessionsWithConfig(SessionsConfig{Store: failingSessionStore{NewSessionMemoryStore()}}))
+	e.GET("/", func(c echo.Context) error {
+		GetSession(c).Set("user", "jon")
+		return nil
+	})
+
+	rec := serveSession(e, nil)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Nil(t, sessionCookie(rec, "_session"))
+}
+
+func TestSessions_skipper(t *testing.T) {
+	e := echo.New()
+	e.Use(SessionsWithConfig(SessionsConfig{
+		Skipper: func(c echo.Context) bool { return true },
+	}))
+	e.GET("/", func(c echo.Context) error {
+		assert.Nil(t, GetSession(c))
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := serveSession(e, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSessions_customContextKey(t *testing.T) {
+	e := echo.New()
+	e.Use(SessionsWithConfig(SessionsConfig{ContextKey: "sess"}))
+	e.GET("/", func(c echo.Context) error {
+		assert.Nil(t, GetSession(c))
+		sess := GetSessionWithKey(c, "sess")
+		if assert.NotNil(t, sess) {
+			sess.Set("user", "jon")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := serveSession(e, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotNil(t, sessionCookie(rec, "_session"))
+}
@@ -1,14 +1,22 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/bytes"
@@ -37,6 +45,24 @@ type (
 		// Optional. Default value false.
 		Browse bool `yaml:"browse"`
 
+		// BrowseRenderer renders directory listing when Browse is enabled.
+		// Optional. Default value `DefaultStaticDirectoryRenderer`.
+		BrowseRenderer StaticDirectoryRenderer `yaml:"-"`
+
+		// BrowsePageSize is the maximum number of entries in one page of directory listing. Page is selected with
+		// `page` query parameter starting from 1.
+		// Optional. Default value 500.
+		BrowsePageSize int `yaml:"browsePageSize"`
+
+		// Exclude patterns of files and directories that are never listed or served. Patterns without "/" are
+		// matched with `path.Match` against every element of the path, so ".git" excludes also everything inside
+		// `.git` directory. Patterns with "/" are matched against the whole path relative to the root (starting
+		// with "/").
+		// Example: `[]string{".*", "*.bak", "/private/*"}` hides dotfiles like `.env` and `.git`, backups and
+		// everything in `/private` directory.
+		// Optional.
+		Exclude []string `yaml:"exclude"`
+
 		// Enable ignoring of the base of the URL path.
 		// Example: when assigning a static middleware to a non root path group,
 		// the filesystem path is not doubled
@@ -46,6 +72,70 @@ type (
 		// Filesystem provides access to the static content.
 		// Optional. Defaults to http.Dir(config.Root)
 		Filesystem http.FileSystem `yaml:"-"`
+
+		// Enable serving of precompressed sibling files. When client accepts the encoding, `<file>.br` (Brotli) or
+		// `<file>.gz` (gzip) is served instead of `<file>` with `Content-Encoding` header set. Brotli is preferred.
+		// Do not combine with Gzip middleware as it would compress the response again.
+		// Optional. Default value false.
+		Precompressed bool `yaml:"precompressed"`
+
+		// Enable strong ETags computed from SHA-256 hash of the file content. Hash is computed once and cached per
+		// file until file modification time or size changes. Useful for file systems without modification times
+		// such as `embed.FS`.
+		// Optional. Default value false.
+		ETag bool `yaml:"etag"`
+
+		// CacheControl rules set `Cache-Control` header of served files. The first rule with matching pattern is
+		// applied. Pattern is matched with `path.Match` against the file name when it does not contain "/" and
+		// against the whole path relative to the root (starting with "/") otherwise.
+		// Example: `[{Pattern: "index.html", Value: "no-cache"}, {Pattern: "/assets/*", Value: "public, max-age=31536000, immutable"}]`
+		// Optional.
+		CacheControl []StaticCacheControlRule `yaml:"cacheControl"`
+	}
+
+	// StaticDirectoryRenderer defines a function to render directory listing.
+	StaticDirectoryRenderer func(c echo.Context, listing StaticDirectoryListing) error
+
+	// StaticDirectoryListing is the directory listing passed to StaticDirectoryRenderer.
+	StaticDirectoryListing struct {
+		// Name is the URL path of the directory.
+		Name  string                 `json:"name"`
+		Files []StaticDirectoryEntry `json:"files"`
+		// Sort is the field entries are sorted by: "name", "size" or "mtime". Directories are always listed first.
+		Sort string `json:"sort"`
+		// Order is the sort order: "asc" or "desc".
+		Order string `json:"order"`
+		// Page is the current page starting from 1.
+		Page int `json:"page"`
+		// Pages is the total number of pages.
+		Pages int `json:"pages"`
+		// Total is the total number of entries in the directory.
+		Total int `json:"total"`
+	}
+
+	// StaticDirectoryEntry is an entry of directory listing.
+	StaticDirectoryEntry struct {
+		Name    string    `json:"name"`
+		Dir     bool      `json:"dir"`
+		Size    int64     `json:"size"`
+		ModTime time.Time `json:"modTime"`
+	}
+
+	// StaticCacheControlRule defines `Cache-Control` header value for files matching the pattern.
+	StaticCacheControlRule struct {
+		Pattern string `yaml:"pattern"`
+		Value   string `yaml:"value"`
+	}
+
+	staticETagCache struct {
+		mutex sync.RWMutex
+		etags map[string]staticETag
+	}
+
+	staticETag struct {
+		modTime time.Time
+		size    int64
+		etag    string
 	}
 )
 
@@ -89,6 +179,9 @@ const html = `
 			color: #707070;
 			font-size: 12px;
 		}
+		nav a {
+			padding: 4px 16px;
+		}
 		li a:hover {
 			opacity: 0.50;
 		}
@@ -104,6 +197,11 @@ const html = `
 	<header>
 		{{ .Name }}
 	</header>
+	<nav>
+		<a href="?sort=name&order={{ if and (eq .Sort "name") (eq .Order "asc") }}desc{{ else }}asc{{ end }}">name</a>
+		<a href="?sort=size&order={{ if and (eq .Sort "size") (eq .Order "asc") }}desc{{ else }}asc{{ end }}">size</a>
+		<a href="?sort=mtime&order={{ if and (eq .Sort "mtime") (eq .Order "asc") }}desc{{ else }}asc{{ end }}">modified</a>
+	</nav>
 	<ul>
 		{{ range .Files }}
 		<li>
@@ -112,11 +210,19 @@ const html = `
 			<a class="dir" href="{{ $name }}">{{ $name }}</a>
 			{{ else }}
 			<a class="file" href="{{ .Name }}">{{ .Name }}</a>
-			<span>{{ .Size }}</span>
+			<span>{{ size .Size }}</span>
+			<span>{{ .ModTime.Format "2006-01-02 15:04:05" }}</span>
 		{{ end }}
 		</li>
 		{{ end }}
   </ul>
+	{{ if gt .Pages 1 }}
+	<nav>
+		{{ if gt .Page 1 }}<a href="?sort={{ .Sort }}&order={{ .Order }}&page={{ dec .Page }}">previous</a>{{ end }}
+		<span>{{ .Page }} / {{ .Pages }}</span>
+		{{ if lt .Page .Pages }}<a href="?sort={{ .Sort }}&order={{ .Order }}&page={{ inc .Page }}">next</a>{{ end }}
+	</nav>
+	{{ end }}
 </body>
 </html>
 `
@@ -124,9 +230,17 @@ const html = `
 var (
 	// DefaultStaticConfig is the default Static middleware config.
 	DefaultStaticConfig = StaticConfig{
-		Skipper: DefaultSkipper,
-		Index:   "index.html",
+		Skipper:        DefaultSkipper,
+		Index:          "index.html",
+		BrowseRenderer: DefaultStaticDirectoryRenderer,
+		BrowsePageSize: 500,
 	}
+
+	staticDirectoryTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+		"size": func(size int64) string { return bytes.Format(size) },
+		"inc":  func(i int) int { return i + 1 },
+		"dec":  func(i int) int { return i - 1 },
+	}).Parse(html))
 )
 
 // Static returns a Static middleware to serves static content from the provided
@@ -150,15 +264,31 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 	if config.Index == "" {
 		config.Index = DefaultStaticConfig.Index
 	}
+	if config.BrowseRenderer == nil {
+		config.BrowseRenderer = DefaultStaticConfig.BrowseRenderer
+	}
+	if config.BrowsePageSize <= 0 {
+		config.BrowsePageSize = DefaultStaticConfig.BrowsePageSize
+	}
 	if config.Filesystem == nil {
 		config.Filesystem = http.Dir(config.Root)
 		config.Root = "."
 	}
 
-	// Index template
-	t, err := template.New("index").Parse(html)
-	if err != nil {
-		panic(fmt.Sprintf("echo: %v", err))
+	for _, rule := range config.CacheControl {
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			panic(fmt.Sprintf("echo: invalid static cache control pattern=%s", rule.Pattern))
+		}
+	}
+	for _, pattern := range config.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("echo: invalid static exclude pattern=%s", pattern))
+		}
+	}
+
+	var etags *staticETagCache
+	if config.ETag {
+		etags = &staticETagCache{etags: map[string]staticETag{}}
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -186,7 +316,7 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 				}
 			}
 
-			file, err := openFile(config.Filesystem, name)
+			file, err := openStaticFile(config, name)
 			if err != nil {
 				if !os.IsNotExist(err) {
 					return err
@@ -201,7 +331,8 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 					return err
 				}
 
-				file, err = openFile(config.Filesystem, filepath.Join(config.Root, config.Index))
+				name = filepath.Join(config.Root, config.Index)
+				file, err = openStaticFile(config, name)
 				if err != nil {
 					return err
 				}
@@ -215,10 +346,11 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 			}
 
 			if info.IsDir() {
-				index, err := openFile(config.Filesystem, filepath.Join(name, config.Index))
+				indexName := filepath.Join(name, config.Index)
+				index, err := openStaticFile(config, indexName)
 				if err != nil {
 					if config.Browse {
-						return listDir(t, name, file, c.Response())
+						return listDir(c, config, name, file)
 					}
 
 					if os.IsNotExist(err) {
@@ -233,14 +365,185 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 					return err
 				}
 
-				return serveFile(c, index, info)
+				return serveStaticFile(c, config, etags, indexName, index, info)
 			}
 
-			return serveFile(c, file, info)
+			return serveStaticFile(c, config, etags, name, file, info)
 		}
 	}
 }
 
+// serveStaticFile serves file with name (path within config.Filesystem) applying cache control rules, precompressed
+// variants and content hash ETags.
+func serveStaticFile(c echo.Context, config StaticConfig, etags *staticETagCache, name string, file http.File, info os.FileInfo) error {
+	res := c.Response()
+	if value := staticCacheControl(config.CacheControl, config.Root, name); value != "" {
+		res.Header().Set(echo.HeaderCacheControl, value)
+	}
+
+	if config.Precompressed {
+		res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+		acceptEncoding := c.Request().Header.Get(echo.HeaderAcceptEncoding)
+		for _, enc := range staticPrecompressedEncodings {
+			if !acceptsEncoding(acceptEncoding, enc.encoding) {
+				continue
+			}
+			compressed, err := openStaticFile(config, name+enc.extension)
+			if err != nil {
+				continue
+			}
+			defer compressed.Close()
+			compressedInfo, err := compressed.Stat()
+			if err != nil || compressedInfo.IsDir() {
+				continue
+			}
+
+			// content type must describe the original file and not the compressed one
+			contentType, err := staticContentType(file, info)
+			if err != nil {
+				return err
+			}
+			res.Header().Set(echo.HeaderContentType, contentType)
+			res.Header().Set(echo.HeaderContentEncoding, enc.encoding)
+			name, file, info = name+enc.extension, compressed, compressedInfo
+			break
+		}
+	}
+
+	if etags != nil {
+		etag, err := etags.get(name, file, info)
+		if err != nil {
+			return err
+		}
+		res.Header().Set(echo.HeaderETag, etag)
+	}
+
+	return serveFile(c, file, info)
+}
+
+var staticPrecompressedEncodings = []struct {
+	encoding  string
+	extension string
+}{
+	{encoding: "br", extension: ".br"},
+	{encoding: gzipScheme, extension: ".gz"},
+}
+
+// staticRelativePath returns path of the file name relative to the root with "/" as separator and prefix.
+func staticRelativePath(root, name string) string {
+	rel := name
+	if root = filepath.Clean(root); root != "." {
+		rel = strings.TrimPrefix(name, root)
+	}
+	return path.Clean("/" + filepath.ToSlash(rel))
+}
+
+// openStaticFile opens file with name unless it is excluded by config.Exclude patterns. Every file that is served
+// (requested file, index file and precompressed variant) must be opened with it.
+func openStaticFile(config StaticConfig, name string) (http.File, error) {
+	if isStaticExcluded(config.Exclude, staticRelativePath(config.Root, name)) {
+		return nil, os.ErrNotExist
+	}
+	return openFile(config.Filesystem, name)
+}
+
+func isStaticExcluded(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+			continue
+		}
+		for _, element := range strings.Split(rel, "/") {
+			if element == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, element); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func staticCacheControl(rules []StaticCacheControlRule, root, name string) string {
+	rel := staticRelativePath(root, name)
+	for _, rule := range rules {
+		target := rel
+		if !strings.Contains(rule.Pattern, "/") {
+			target = path.Base(rel)
+		}
+		if ok, _ := path.Match(rule.Pattern, target); ok {
+			return rule.Value
+		}
+	}
+	return ""
+}
+
+// staticContentType returns content type of the file from its extension or by sniffing its content.
+func staticContentType(file http.File, info os.FileInfo) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(info.Name())); contentType != "" {
+		return contentType, nil
+	}
+	var buf [512]byte
+	n, _ := io.ReadFull(file, buf[:])
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// acceptsEncoding returns true when Accept-Encoding header value allows the encoding, either explicitly or with `*`.
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	accepted := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, quality := part, ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			coding, quality = part[:i], part[i+1:]
+		}
+		coding = strings.TrimSpace(coding)
+		if !strings.EqualFold(coding, encoding) && coding != "*" {
+			continue
+		}
+		quality = strings.ReplaceAll(strings.TrimSpace(quality), " ", "")
+		rejected := strings.HasPrefix(quality, "q=0") && strings.Trim(quality[3:], ".0") == ""
+		if coding == "*" {
+			// explicit coding takes precedence over wildcard
+			if !rejected && !accepted {
+				accepted = true
+			}
+			continue
+		}
+		return !rejected
+	}
+	return accepted
+}
+
+// get returns ETag of the file computing it when file is not cached or has changed since it was cached.
+func (ec *staticETagCache) get(name string, file http.File, info os.FileInfo) (string, error) {
+	ec.mutex.RLock()
+	cached, ok := ec.etags[name]
+	ec.mutex.RUnlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.etag, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:18]) + `"`
+
+	ec.mutex.Lock()
+	ec.etags[name] = staticETag{modTime: info.ModTime(), size: info.Size(), etag: etag}
+	ec.mutex.Unlock()
+	return etag, nil
+}
+
 func openFile(fs http.FileSystem, name string) (http.File, error) {
 	pathWithSlashes := filepath.ToSlash(name)
 	return fs.Open(pathWithSlashes)
@@ -251,26 +554,94 @@ func serveFile(c echo.Context, file http.File, info os.FileInfo) error {
 	return nil
 }
 
-func listDir(t *template.Template, name string, dir http.File, res *echo.Response) (err error) {
+func listDir(c echo.Context, config StaticConfig, name string, dir http.File) error {
 	files, err := dir.Readdir(-1)
 	if err != nil {
-		return
+		return err
 	}
 
-	// Create directory index
-	res.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
-	data := struct {
-		Name  string
-		Files []interface{}
-	}{
-		Name: name,
-	}
+	rel := staticRelativePath(config.Root, name)
+	entries := make([]StaticDirectoryEntry, 0, len(files))
 	for _, f := range files {
-		data.Files = append(data.Files, struct {
-			Name string
-			Dir  bool
-			Size string
-		}{f.Name(), f.IsDir(), bytes.Format(f.Size())})
+		if isStaticExcluded(config.Exclude, path.Join(rel, f.Name())) {
+			continue
+		}
+		entries = append(entries, StaticDirectoryEntry{
+			Name:    f.Name(),
+			Dir:     f.IsDir(),
+			Size:    f.Size(),
+			ModTime: f.ModTime(),
+		})
+	}
+
+	listing := StaticDirectoryListing{
+		Name:  c.Request().URL.Path,
+		Sort:  c.QueryParam("sort"),
+		Order: c.QueryParam("order"),
+		Total: len(entries),
+	}
+	if listing.Sort != "size" && listing.Sort != "mtime" {
+		listing.Sort = "name"
+	}
+	if listing.Order != "desc" {
+		listing.Order = "asc"
+	}
+	sortStaticDirectoryEntries(entries, listing.Sort, listing.Order == "desc")
+
+	listing.Pages = (len(entries) + config.BrowsePageSize - 1) / config.BrowsePageSize
+	if listing.Pages == 0 {
+		listing.Pages = 1
+	}
+	listing.Page, _ = strconv.Atoi(c.QueryParam("page"))
+	if listing.Page < 1 {
+		listing.Page = 1
+	} else if listing.Page > listing.Pages {
+		listing.Page = listing.Pages
+	}
+	from := (listing.Page - 1) * config.BrowsePageSize
+	to := from + config.BrowsePageSize
+	if to > len(entries) {
+		to = len(entries)
 	}
-	return t.Execute(res, data)
+	listing.Files = entries[from:to]
+
+	return config.BrowseRenderer(c, listing)
+}
+
+func sortStaticDirectoryEntries(entries []StaticDirectoryEntry, by string, desc bool) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Dir != b.Dir {
+			return a.Dir
+		}
+		if desc {
+			a, b = b, a
+		}
+		switch by {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "mtime":
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+		}
+		return a.Name < b.Name
+	})
+}
+
+// DefaultStaticDirectoryRenderer renders directory listing as JSON when client accepts `application/json` but not
+// `text/html` and as HTML page otherwise. Response has `Vary: Accept` header so caches keep both representations.
+func DefaultStaticDirectoryRenderer(c echo.Context, listing StaticDirectoryListing) error {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if strings.Contains(accept, echo.MIMEApplicationJSON) && !strings.Contains(accept, echo.MIMETextHTML) {
+		return c.JSON(http.StatusOK, listing)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMETextHTMLCharsetUTF8)
+	res.WriteHeader(http.StatusOK)
+	return staticDirectoryTemplate.Execute(res, listing)
 }
@@ -3,12 +3,15 @@
 package middleware
 
 import (
+	"encoding/json"
 	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -104,3 +107,357 @@ func TestStatic_CustomFS(t *testing.T) {
 		})
 	}
 }
+
+func TestStatic_Precompressed(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"app.js":          &fstest.MapFile{Data: []byte("plain")},
+		"app.js.br":       &fstest.MapFile{Data: []byte("brotli")},
+		"app.js.gz":       &fstest.MapFile{Data: []byte("gzip")},
+		"style.css":       &fstest.MapFile{Data: []byte("plain css")},
+		"style.css.gz":    &fstest.MapFile{Data: []byte("gzip css")},
+		"data.unknown":    &fstest.MapFile{Data: []byte("<html><body>sniffed</body></html>")},
+		"data.unknown.gz": &fstest.MapFile{Data: []byte{0x1f, 0x8b, 0x08}},
+	}
+
+	var testCases = []struct {
+		name                 string
+		whenURL              string
+		whenAcceptEncoding   string
+		expectBody           string
+		expectEncoding       string
+		expectContentTypePfx string
+	}{
+		{
+			name:                 "ok, brotli is preferred",
+			whenURL:              "/app.js",
+			whenAcceptEncoding:   "gzip, deflate, br",
+			expectBody:           "brotli",
+			expectEncoding:       "br",
+			expectContentTypePfx: "text/javascript",
+		},
+		{
+			name:                 "ok, gzip when brotli is rejected",
+			whenURL:              "/app.js",
+			whenAcceptEncoding:   "gzip, br;q=0",
+			expectBody:           "gzip",
+			expectEncoding:       "gzip",
+			expectContentTypePfx: "text/javascript",
+		},
+		{
+			name:                 "ok, gzip when brotli variant does not exist",
+			whenURL:              "/style.css",
+			whenAcceptEncoding:   "br, gzip",
+			expectBody:           "gzip css",
+			expectEncoding:       "gzip",
+			expectContentTypePfx: "text/css",
+		},
+		{
+			name:                 "ok, original when client does not accept encoding",
+			whenURL:              "/app.js",
+			expectBody:           "plain",
+			expectContentTypePfx: "text/javascript",
+		},
+		{
+			name:                 "ok, content type is sniffed from original file",
+			whenURL:              "/data.unknown",
+			whenAcceptEncoding:   "*",
+			expectBody:           string([]byte{0x1f, 0x8b, 0x08}),
+			expectEncoding:       "gzip",
+			expectContentTypePfx: "text/html",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(StaticWithConfig(StaticConfig{
+				Filesystem:    http.FS(filesystem),
+				Precompressed: true,
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			if tc.whenAcceptEncoding != "" {
+				req.Header.Set(echo.HeaderAcceptEncoding, tc.whenAcceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectEncoding, rec.Header().Get(echo.HeaderContentEncoding))
+			assert.True(t, strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), tc.expectContentTypePfx))
+			assert.Equal(t, echo.HeaderAcceptEncoding, rec.Header().Get(echo.HeaderVary))
+		})
+	}
+}
+
+func TestStatic_ETag(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"app.js":    &fstest.MapFile{Data: []byte("plain")},
+		"app.js.gz": &fstest.MapFile{Data: []byte("gzip")},
+	}
+	e := echo.New()
+	e.Use(StaticWithConfig(StaticConfig{
+		Filesystem:    http.FS(filesystem),
+		Precompressed: true,
+		ETag:          true,
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	etag := rec.Header().Get(echo.HeaderETag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Regexp(t, `^"[A-Za-z0-9_-]{24}"$`, etag)
+
+	// compressed variant is a different representation and has different ETag
+	req = httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.NotEqual(t, etag, rec.Header().Get(echo.HeaderETag))
+
+	req = httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// cached ETag is recomputed when file changes
+	filesystem["app.js"] = &fstest.MapFile{Data: []byte("changed"), ModTime: time.Now()}
+	req = httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get(echo.HeaderETag))
+	assert.Equal(t, "changed", rec.Body.String())
+}
+
+func TestStatic_CacheControl(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"index.html":           &fstest.MapFile{Data: []byte("index")},
+		"assets/app.3f9a1c.js": &fstest.MapFile{Data: []byte("app")},
+		"assets/logo.png":      &fstest.MapFile{Data: []byte("logo")},
+		"robots.txt":           &fstest.MapFile{Data: []byte("robots")},
+	}
+	e := echo.New()
+	e.Use(StaticWithConfig(StaticConfig{
+		Filesystem: http.FS(filesystem),
+		CacheControl: []StaticCacheControlRule{
+			{Pattern: "index.html", Value: "no-cache"},
+			{Pattern: "/assets/*.*.js", Value: "public, max-age=31536000, immutable"},
+			{Pattern: "/assets/*", Value: "public, max-age=3600"},
+		},
+	}))
+
+	var testCases = []struct {
+		whenURL            string
+		expectCacheControl string
+	}{
+		{whenURL: "/", expectCacheControl: "no-cache"},
+		{whenURL: "/index.html", expectCacheControl: "no-cache"},
+		{whenURL: "/assets/app.3f9a1c.js", expectCacheControl: "public, max-age=31536000, immutable"},
+		{whenURL: "/assets/logo.png", expectCacheControl: "public, max-age=3600"},
+		{whenURL: "/robots.txt", expectCacheControl: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.whenURL, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCacheControl, rec.Header().Get(echo.HeaderCacheControl))
+		})
+	}
+
+	assert.Panics(t, func() {
+		StaticWithConfig(StaticConfig{CacheControl: []StaticCacheControlRule{{Pattern: "[", Value: "no-cache"}}})
+	})
+}
+
+func TestStatic_Browse(t *testing.T) {
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	filesystem := fstest.MapFS{
+		"docs/b.txt":        &fstest.MapFile{Data: []byte("bb"), ModTime: t0.Add(2 * time.Hour)},
+		"docs/a.txt":        &fstest.MapFile{Data: []byte("aaa"), ModTime: t0.Add(3 * time.Hour)},
+		"docs/c.txt":        &fstest.MapFile{Data: []byte("c"), ModTime: t0.Add(1 * time.Hour)},
+		"docs/sub/d.txt":    &fstest.MapFile{Data: []byte("d"), ModTime: t0},
+		"docs/.env":         &fstest.MapFile{Data: []byte("SECRET=1")},
+		"docs/.git/config":  &fstest.MapFile{Data: []byte("[core]")},
+		"docs/private/x.md": &fstest.MapFile{Data: []byte("x")},
+	}
+
+	var testCases = []struct {
+		name        string
+		whenURL     string
+		expectNames []string
+		expectPage  int
+		expectPages int
+	}{
+		{
+			name:        "ok, sorted by name with directories first",
+			whenURL:     "/docs/",
+			expectNames: []string{"sub", "a.txt", "b.txt"},
+			expectPage:  1,
+			expectPages: 2,
+		},
+		{
+			name:        "ok, second page",
+			whenURL:     "/docs/?page=2",
+			expectNames: []string{"c.txt"},
+			expectPage:  2,
+			expectPages: 2,
+		},
+		{
+			name:        "ok, page out of range is clamped",
+			whenURL:     "/docs/?page=10",
+			expectNames: []string{"c.txt"},
+			expectPage:  2,
+			expectPages: 2,
+		},
+		{
+			name:        "ok, sorted by size descending",
+			whenURL:     "/docs/?sort=size&order=desc",
+			expectNames: []string{"sub", "a.txt", "b.txt"},
+			expectPage:  1,
+			expectPages: 2,
+		},
+		{
+			name:        "ok, sorted by modification time",
+			whenURL:     "/docs/?sort=mtime",
+			expectNames: []string{"sub", "c.txt", "b.txt"},
+			expectPage:  1,
+			expectPages: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(StaticWithConfig(StaticConfig{
+				Filesystem:     http.FS(filesystem),
+				Browse:         true,
+				BrowsePageSize: 3,
+				Exclude:        []string{".*", "/docs/private"},
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+			var listing StaticDirectoryListing
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listing))
+			names := make([]string, 0)
+			for _, f := range listing.Files {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, tc.expectNames, names)
+			assert.Equal(t, "/docs/", listing.Name)
+			assert.Equal(t, 4, listing.Total)
+			assert.Equal(t, tc.expectPage, listing.Page)
+			assert.Equal(t, tc.expectPages, listing.Pages)
+		})
+	}
+}
+
+func TestStatic_BrowseHTML(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("aaa"), ModTime: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+		".env":  &fstest.MapFile{Data: []byte("SECRET=1")},
+	}
+	e := echo.New()
+	e.Use(StaticWithConfig(StaticConfig{
+		Filesystem: http.FS(filesystem),
+		Browse:     true,
+		Exclude:    []string{".*"},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, "text/html,application/json;q=0.9")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMETextHTMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+	assert.Contains(t, rec.Body.String(), `<a class="file" href="a.txt">a.txt</a>`)
+	assert.Contains(t, rec.Body.String(), "2021-01-02 03:04:05")
+	assert.NotContains(t, rec.Body.String(), ".env")
+}
+
+func TestStatic_Exclude(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"index.html":       &fstest.MapFile{Data: []byte("index")},
+		".env":             &fstest.MapFile{Data: []byte("SECRET=1")},
+		".git/config":      &fstest.MapFile{Data: []byte("[core]")},
+		"backup/file.bak":  &fstest.MapFile{Data: []byte("backup")},
+		"public/file.txt":  &fstest.MapFile{Data: []byte("public")},
+		"private/file.txt": &fstest.MapFile{Data: []byte("private")},
+	}
+
+	var testCases = []struct {
+		whenURL    string
+		expectCode int
+	}{
+		{whenURL: "/public/file.txt", expectCode: http.StatusOK},
+		{whenURL: "/.env", expectCode: http.StatusNotFound},
+		{whenURL: "/.git/config", expectCode: http.StatusNotFound},
+		{whenURL: "/backup/file.bak", expectCode: http.StatusNotFound},
+		{whenURL: "/private/file.txt", expectCode: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.whenURL, func(t *testing.T) {
+			e := echo.New()
+			e.Use(StaticWithConfig(StaticConfig{
+				Filesystem: http.FS(filesystem),
+				Exclude:    []string{".*", "*.bak", "/private/*"},
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+		})
+	}
+
+	assert.Panics(t, func() {
+		StaticWithConfig(StaticConfig{Exclude: []string{"["}})
+	})
+}
+
+func TestStatic_ExcludeServedVariants(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"app.js":          &fstest.MapFile{Data: []byte("plain")},
+		"app.js.br":       &fstest.MapFile{Data: []byte("brotli")},
+		"app.js.gz":       &fstest.MapFile{Data: []byte("gzip")},
+		"docs/index.html": &fstest.MapFile{Data: []byte("docs")},
+		"docs/a.txt":      &fstest.MapFile{Data: []byte("a")},
+	}
+	e := echo.New()
+	e.Use(StaticWithConfig(StaticConfig{
+		Filesystem:    http.FS(filesystem),
+		Precompressed: true,
+		Exclude:       []string{"*.br", "/docs/index.html"},
+	}))
+
+	// excluded precompressed variant is skipped
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "br, gzip")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Body.String())
+	assert.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+
+	// excluded index file is not served for directory
+	req = httptest.NewRequest(http.MethodGet, "/docs/", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -306,3 +306,27 @@ func TestStatic_GroupWithStatic(t *testing.T) {
 		})
 	}
 }
+
+func TestAcceptsEncoding(t *testing.T) {
+	var testCases = []struct {
+		acceptEncoding string
+		encoding       string
+		expect         bool
+	}{
+		{acceptEncoding: "gzip, br", encoding: "br", expect: true},
+		{acceptEncoding: "gzip", encoding: "br", expect: false},
+		{acceptEncoding: "", encoding: "gzip", expect: false},
+		{acceptEncoding: "GZIP;q=0.5", encoding: "gzip", expect: true},
+		{acceptEncoding: "br;q=0", encoding: "br", expect: false},
+		{acceptEncoding: "br; q=0.000", encoding: "br", expect: false},
+		{acceptEncoding: "*", encoding: "br", expect: true},
+		{acceptEncoding: "*;q=0", encoding: "br", expect: false},
+		{acceptEncoding: "*, br;q=0", encoding: "br", expect: false},
+		{acceptEncoding: "br;q=0, *", encoding: "br", expect: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.acceptEncoding+"/"+tc.encoding, func(t *testing.T) {
+			assert.Equal(t, tc.expect, acceptsEncoding(tc.acceptEncoding, tc.encoding))
+		})
+	}
+}
@@ -0,0 +1,445 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// TusConfig defines the config for tus resumable upload endpoints.
+	TusConfig struct {
+		// Store keeps upload state and content.
+		// Required.
+		Store TusStore
+
+		// MaxSize is the maximum length of an upload in bytes.
+		// Optional. Default value 0 (unlimited).
+		MaxSize int64
+
+		// Expiration is the duration after the last change of an upload when unfinished upload expires and is
+		// removed. Negative value disables expiration.
+		// Optional. Default value 24 hours.
+		Expiration time.Duration
+
+		// OnCreate is called before new upload is created. It can be used to authorize the request and to validate
+		// upload metadata. Returned error aborts the creation.
+		// Optional.
+		OnCreate func(c echo.Context, upload *TusUpload) error
+
+		// OnComplete is called when all bytes of an upload have been received. Returned error is sent as the response
+		// to the last PATCH request but the upload stays complete.
+		// Optional.
+		OnComplete func(c echo.Context, upload *TusUpload) error
+	}
+
+	// TusUpload describes state of a resumable upload.
+	TusUpload struct {
+		ID        string            `json:"id"`
+		Length    int64             `json:"length"`
+		Offset    int64             `json:"offset"`
+		Metadata  map[string]string `json:"metadata,omitempty"`
+		CreatedAt time.Time         `json:"created_at"`
+		ExpiresAt time.Time         `json:"expires_at,omitempty"`
+	}
+
+	// TusStore is the storage backend for tus uploads.
+	TusStore interface {
+		// Create stores new upload with empty content.
+		Create(upload *TusUpload) error
+		// Get returns the upload with given ID or `ErrTusUploadNotFound`.
+		Get(id string) (*TusUpload, error)
+		// WriteChunk writes content at offset and discards content previously written after the end of the chunk.
+		// It returns the number of bytes written even when reading from r fails. WriteChunk must not change the
+		// stored offset.
+		WriteChunk(id string, offset int64, r io.Reader) (int64, error)
+		// Update persists changed upload state.
+		Update(upload *TusUpload) error
+		// Delete removes the upload and its content.
+		Delete(id string) error
+	}
+
+	tusHandler struct {
+		config TusConfig
+		mutex  sync.Mutex
+		locked map[string]struct{}
+	}
+)
+
+const (
+	tusVersion = "1.0.0"
+
+	tusHeaderResumable         = "Tus-Resumable"
+	tusHeaderVersion           = "Tus-Version"
+	tusHeaderExtension         = "Tus-Extension"
+	tusHeaderMaxSize           = "Tus-Max-Size"
+	tusHeaderChecksumAlgorithm = "Tus-Checksum-Algorithm"
+	tusHeaderUploadLength      = "Upload-Length"
+	tusHeaderUploadOffset      = "Upload-Offset"
+	tusHeaderUploadMetadata    = "Upload-Metadata"
+	tusHeaderUploadExpires     = "Upload-Expires"
+	tusHeaderUploadChecksum    = "Upload-Checksum"
+
+	tusContentType = "application/offset+octet-stream"
+
+	// tusStatusChecksumMismatch is the status defined by tus checksum extension.
+	tusStatusChecksumMismatch = 460
+)
+
+var (
+	// DefaultTusConfig is the default tus config.
+	DefaultTusConfig = TusConfig{
+		Expiration: 24 * time.Hour,
+	}
+
+	// ErrTusUploadNotFound is returned by TusStore when the upload does not exist.
+	ErrTusUploadNotFound = errors.New("tus: upload not found")
+
+	tusChecksumAlgorithms = map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+	}
+)
+
+// MountTus registers endpoints of tus 1.0 resumable upload protocol (https://tus.io/protocols/resumable-upload.html)
+// with creation, creation-with-upload, expiration, checksum and termination extensions on the group. Uploads are
+// created with POST request to the group prefix and addressed as `<prefix>/<id>`.
+//
+// Concurrent PATCH requests for the same upload are rejected with "423 - Locked". Locks are held in process so
+// multiple instances sharing the store must route requests of an upload to the same instance.
+//
+// Expired uploads are removed when they are requested. Uploads which are never requested again are not removed by
+// the middleware, the caller must remove them periodically, e.g. by calling `TusFileStore.Cleanup()` from a ticker.
+func MountTus(g *echo.Group, config TusConfig) {
+	if config.Store == nil {
+		panic("echo: tus middleware requires a store")
+	}
+	if config.Expiration == 0 {
+		config.Expiration = DefaultTusConfig.Expiration
+	}
+
+	h := &tusHandler{config: config, locked: map[string]struct{}{}}
+	for _, path := range []string{"", "/"} {
+		g.OPTIONS(path, h.options, h.protocol)
+		g.POST(path, h.create, h.protocol)
+	}
+	g.OPTIONS("/:id", h.options, h.protocol)
+	g.HEAD("/:id", h.head, h.protocol)
+	g.PATCH("/:id", h.patch, h.protocol)
+	g.DELETE("/:id", h.delete, h.protocol)
+}
+
+// protocol adds `Tus-Resumable` header to every response and rejects requests of unsupported protocol versions.
+func (h *tusHandler) protocol(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res := c.Response()
+		res.Header().Set(tusHeaderResumable, tusVersion)
+		res.Header().Set(echo.HeaderCacheControl, "no-store")
+		if c.Request().Method != http.MethodOptions && c.Request().Header.Get(tusHeaderResumable) != tusVersion {
+			res.Header().Set(tusHeaderVersion, tusVersion)
+			return echo.NewHTTPError(http.StatusPreconditionFailed, "unsupported tus version")
+		}
+		return next(c)
+	}
+}
+
+func (h *tusHandler) options(c echo.Context) error {
+	header := c.Response().Header()
+	header.Set(tusHeaderVersion, tusVersion)
+	extensions := "creation,creation-with-upload,checksum,termination"
+	if h.config.Expiration > 0 {
+		extensions += ",expiration"
+	}
+	header.Set(tusHeaderExtension, extensions)
+	if h.config.MaxSize > 0 {
+		header.Set(tusHeaderMaxSize, strconv.FormatInt(h.config.MaxSize, 10))
+	}
+	algorithms := make([]string, 0, len(tusChecksumAlgorithms))
+	for name := range tusChecksumAlgorithms {
+		algorithms = append(algorithms, name)
+	}
+	sort.Strings(algorithms)
+	header.Set(tusHeaderChecksumAlgorithm, strings.Join(algorithms, ","))
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *tusHandler) create(c echo.Context) error {
+	req := c.Request()
+	length, err := strconv.ParseInt(req.Header.Get(tusHeaderUploadLength), 10, 64)
+	if err != nil || length < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid Upload-Length header")
+	}
+	if h.config.MaxSize > 0 && length > h.config.MaxSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "upload exceeds maximum size")
+	}
+	metadata, err := parseTusMetadata(req.Header.Get(tusHeaderUploadMetadata))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid Upload-Metadata header").SetInternal(err)
+	}
+	id, err := newTusID()
+	if err != nil {
+		return err
+	}
+
+	t := now()
+	upload := &TusUpload{ID: id, Length: length, Metadata: metadata, CreatedAt: t}
+	h.touch(upload, t)
+	if h.config.OnCreate != nil {
+		if err := h.config.OnCreate(c, upload); err != nil {
+			return err
+		}
+	}
+	if err := h.config.Store.Create(upload); err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderLocation, strings.TrimSuffix(req.URL.Path, "/")+"/"+id)
+	if req.Header.Get(echo.HeaderContentType) == tusContentType && upload.Length > 0 {
+		// creation-with-upload extension
+		if !h.lock(id) {
+			return echo.NewHTTPError(http.StatusLocked)
+		}
+		defer h.unlock(id)
+		if err := h.write(c, upload); err != nil {
+			return err
+		}
+		res.Header().Set(tusHeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	} else if upload.Length == 0 {
+		if err := h.complete(c, upload); err != nil {
+			return err
+		}
+	}
+	h.setExpires(res.Header(), upload)
+	return c.NoContent(http.StatusCreated)
+}
+
+func (h *tusHandler) head(c echo.Context) error {
+	upload, err := h.get(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	header := c.Response().Header()
+	header.Set(tusHeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	header.Set(tusHeaderUploadLength, strconv.FormatInt(upload.Length, 10))
+	if len(upload.Metadata) > 0 {
+		header.Set(tusHeaderUploadMetadata, formatTusMetadata(upload.Metadata))
+	}
+	h.setExpires(header, upload)
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *tusHandler) patch(c echo.Context) error {
+	req := c.Request()
+	if req.Header.Get(echo.HeaderContentType) != tusContentType {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be "+tusContentType)
+	}
+	offset, err := strconv.ParseInt(req.Header.Get(tusHeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid Upload-Offset header")
+	}
+
+	id := c.Param("id")
+	if !h.lock(id) {
+		return echo.NewHTTPError(http.StatusLocked)
+	}
+	defer h.unlock(id)
+
+	upload, err := h.get(id)
+	if err != nil {
+		return err
+	}
+	if offset != upload.Offset {
+		return echo.NewHTTPError(http.StatusConflict, "Upload-Offset does not match current offset")
+	}
+	if err := h.write(c, upload); err != nil {
+		return err
+	}
+
+	header := c.Response().Header()
+	header.Set(tusHeaderUploadOffset, strconv.FormatInt(upload.Offset, 10))
+	h.setExpires(header, upload)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *tusHandler) delete(c echo.Context) error {
+	id := c.Param("id")
+	if !h.lock(id) {
+		return echo.NewHTTPError(http.StatusLocked)
+	}
+	defer h.unlock(id)
+
+	if _, err := h.get(id); err != nil {
+		return err
+	}
+	if err := h.config.Store.Delete(id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// write appends request body to the upload verifying `Upload-Checksum` header when present. Without checksum bytes
+// received before the connection failed are kept so client can resume from them.
+func (h *tusHandler) write(c echo.Context, upload *TusUpload) error {
+	req := c.Request()
+	var body io.Reader = io.LimitReader(req.Body, upload.Length-upload.Offset)
+
+	var hasher hash.Hash
+	var expected []byte
+	if checksum := req.Header.Get(tusHeaderUploadChecksum); checksum != "" {
+		parts := strings.SplitN(checksum, " ", 2)
+		newHash, ok := tusChecksumAlgorithms[parts[0]]
+		if !ok || len(parts) != 2 {
+			return echo.NewHTTPError(http.StatusBadRequest, "unsupported checksum algorithm")
+		}
+		var err error
+		if expected, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid Upload-Checksum header")
+		}
+		hasher = newHash()
+		body = io.TeeReader(body, hasher)
+	}
+
+	n, err := h.config.Store.WriteChunk(upload.ID, upload.Offset, body)
+	if hasher != nil {
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hasher.Sum(nil), expected) {
+			return echo.NewHTTPError(tusStatusChecksumMismatch, "checksum mismatch")
+		}
+	}
+	if n > 0 {
+		upload.Offset += n
+		h.touch(upload, now())
+		if uErr := h.config.Store.Update(upload); uErr != nil {
+			return uErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if n > 0 && upload.Offset == upload.Length {
+		// upload is completed only by the request writing its last bytes so repeated PATCH requests to finished
+		// upload do not call OnComplete again
+		return h.complete(c, upload)
+	}
+	return nil
+}
+
+func (h *tusHandler) complete(c echo.Context, upload *TusUpload) error {
+	upload.ExpiresAt = time.Time{}
+	if err := h.config.Store.Update(upload); err != nil {
+		return err
+	}
+	if h.config.OnComplete != nil {
+		return h.config.OnComplete(c, upload)
+	}
+	return nil
+}
+
+// get returns the upload removing it when it has expired.
+func (h *tusHandler) get(id string) (*TusUpload, error) {
+	upload, err := h.config.Store.Get(id)
+	if err == ErrTusUploadNotFound {
+		return nil, echo.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if !upload.ExpiresAt.IsZero() && now().After(upload.ExpiresAt) {
+		if err := h.config.Store.Delete(id); err != nil {
+			return nil, err
+		}
+		return nil, echo.NewHTTPError(http.StatusGone, "upload has expired")
+	}
+	return upload, nil
+}
+
+func (h *tusHandler) touch(upload *TusUpload, t time.Time) {
+	if h.config.Expiration > 0 && upload.Offset < upload.Length {
+		upload.ExpiresAt = t.Add(h.config.Expiration)
+	}
+}
+
+func (h *tusHandler) setExpires(header http.Header, upload *TusUpload) {
+	if !upload.ExpiresAt.IsZero() {
+		header.Set(tusHeaderUploadExpires, upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+func (h *tusHandler) lock(id string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, ok := h.locked[id]; ok {
+		return false
+	}
+	h.locked[id] = struct{}{}
+	return true
+}
+
+func (h *tusHandler) unlock(id string) {
+	h.mutex.Lock()
+	delete(h.locked, id)
+	h.mutex.Unlock()
+}
+
+func newTusID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseTusMetadata parses `Upload-Metadata` header in form of `key base64value,key2 base64value2`. Value is optional.
+func parseTusMetadata(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, errors.New("tus: invalid metadata pair")
+		}
+		v := ""
+		if len(parts) == 2 {
+			b, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			v = string(b)
+		}
+		metadata[parts[0]] = v
+	}
+	return metadata, nil
+}
+
+func formatTusMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for k, v := range metadata {
+		if v == "" {
+			pairs = append(pairs, k)
+			continue
+		}
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TusFileStore is a store implementation for tus uploads which keeps every upload in a directory as two files:
+// `<id>.bin` with the content and `<id>.info` with JSON encoded upload state.
+type TusFileStore struct {
+	mutex sync.RWMutex
+	dir   string
+}
+
+const (
+	tusFileContentExt = ".bin"
+	tusFileInfoExt    = ".info"
+)
+
+// NewTusFileStore returns an instance of TusFileStore creating the directory when it does not exist.
+func NewTusFileStore(dir string) (*TusFileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &TusFileStore{dir: dir}, nil
+}
+
+// Create implements TusStore.Create
+func (store *TusFileStore) Create(upload *TusUpload) error {
+	name, ok := store.filename(upload.ID)
+	if !ok {
+		return ErrTusUploadNotFound
+	}
+	f, err := os.OpenFile(name+tusFileContentExt, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return store.Update(upload)
+}
+
+// Get implements TusStore.Get
+func (store *TusFileStore) Get(id string) (*TusUpload, error) {
+	name, ok := store.filename(id)
+	if !ok {
+		return nil, ErrTusUploadNotFound
+	}
+
+	store.mutex.RLock()
+	b, err := ioutil.ReadFile(name + tusFileInfoExt)
+	store.mutex.RUnlock()
+	if os.IsNotExist(err) {
+		return nil, ErrTusUploadNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	upload := new(TusUpload)
+	if err := json.Unmarshal(b, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// WriteChunk implements TusStore.WriteChunk
+func (store *TusFileStore) WriteChunk(id string, offset int64, r io.Reader) (int64, error) {
+	name, ok := store.filename(id)
+	if !ok {
+		return 0, ErrTusUploadNotFound
+	}
+	f, err := os.OpenFile(name+tusFileContentExt, os.O_WRONLY, 0600)
+	if os.IsNotExist(err) {
+		return 0, ErrTusUploadNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if tErr := f.Truncate(offset + n); tErr != nil && err == nil {
+		err = tErr
+	}
+	return n, err
+}
+
+// Update implements TusStore.Update
+func (store *TusFileStore) Update(upload *TusUpload) error {
+	name, ok := store.filename(upload.ID)
+	if !ok {
+		return ErrTusUploadNotFound
+	}
+	b, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	// Write to temporary file first so concurrent readers never see partially written state.
+	tmp := name + tusFileInfoExt + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name+tusFileInfoExt)
+}
+
+// Delete implements TusStore.Delete
+func (store *TusFileStore) Delete(id string) error {
+	name, ok := store.filename(id)
+	if !ok {
+		return nil
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for _, ext := range []string{tusFileInfoExt, tusFileContentExt} {
+		if err := os.Remove(name + ext); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open returns the content of the upload. Content of unfinished uploads is incomplete.
+func (store *TusFileStore) Open(id string) (*os.File, error) {
+	name, ok := store.filename(id)
+	if !ok {
+		return nil, ErrTusUploadNotFound
+	}
+	f, err := os.Open(name + tusFileContentExt)
+	if os.IsNotExist(err) {
+		return nil, ErrTusUploadNotFound
+	}
+	return f, err
+}
+
+// Cleanup removes all expired uploads from the store directory. It is not called by the middleware and should be
+// called periodically by the application.
+func (store *TusFileStore) Cleanup() error {
+	files, err := filepath.Glob(filepath.Join(store.dir, "*"+tusFileInfoExt))
+	if err != nil {
+		return err
+	}
+	t := now()
+	for _, name := range files {
+		id := strings.TrimSuffix(filepath.Base(name), tusFileInfoExt)
+		upload, err := store.Get(id)
+		if err == ErrTusUploadNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !upload.ExpiresAt.IsZero() && t.After(upload.ExpiresAt) {
+			if err := store.Delete(id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// filename returns path to upload files without extension. ID is only accepted when it has the form of IDs generated
+// by the middleware so it can not be used to traverse out of store directory.
+func (store *TusFileStore) filename(id string) (string, bool) {
+	if len(id) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return filepath.Join(store.dir, id), true
+}
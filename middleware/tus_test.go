@@ -0,0 +1,282 @@
+package middleware
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTusTestServer(t *testing.T, config TusConfig) (*echo.Echo, *TusFileStore) {
+	store, err := NewTusFileStore(t.TempDir())
+	assert.NoError(t, err)
+	config.Store = store
+	e := echo.New()
+	MountTus(e.Group("/files"), config)
+	return e, store
+}
+
+func tusRequest(e *echo.Echo, method, target string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(tusHeaderResumable, tusVersion)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTus(t *testing.T) {
+	current := testResponseCacheNow(t)
+	var completed *TusUpload
+	completions := 0
+	e, store := newTusTestServer(t, TusConfig{
+		OnComplete: func(c echo.Context, upload *TusUpload) error {
+			completions++
+			completed = upload
+			return nil
+		},
+	})
+
+	rec := tusRequest(e, http.MethodPost, "/files", "", map[string]string{
+		tusHeaderUploadLength:   "11",
+		tusHeaderUploadMetadata: "filename " + base64.StdEncoding.EncodeToString([]byte("hello.txt")) + ",public",
+	})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, tusVersion, rec.Header().Get(tusHeaderResumable))
+	assert.Equal(t, "Fri, 22 Oct 2021 07:28:00 GMT", rec.Header().Get(tusHeaderUploadExpires))
+	location := rec.Header().Get(echo.HeaderLocation)
+	assert.True(t, strings.HasPrefix(location, "/files/"))
+
+	patch := func(offset, body string) *httptest.ResponseRecorder {
+		return tusRequest(e, http.MethodPatch, location, body, map[string]string{
+			echo.HeaderContentType: tusContentType,
+			tusHeaderUploadOffset:  offset,
+		})
+	}
+
+	rec = patch("0", "hello")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get(tusHeaderUploadOffset))
+
+	rec = patch("0", "hello")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	*current = current.Add(time.Hour)
+	rec = tusRequest(e, http.MethodHead, location, "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get(tusHeaderUploadOffset))
+	assert.Equal(t, "11", rec.Header().Get(tusHeaderUploadLength))
+	assert.Equal(t, "filename aGVsbG8udHh0,public", rec.Header().Get(tusHeaderUploadMetadata))
+	assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+
+	// content beyond upload length is not accepted
+	rec = patch("5", " world and more")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "11", rec.Header().Get(tusHeaderUploadOffset))
+	assert.Empty(t, rec.Header().Get(tusHeaderUploadExpires))
+
+	if assert.NotNil(t, completed) {
+		assert.Equal(t, int64(11), completed.Offset)
+		assert.Equal(t, map[string]string{"filename": "hello.txt", "public": ""}, completed.Metadata)
+		f, err := store.Open(completed.ID)
+		assert.NoError(t, err)
+		b, _ := ioutil.ReadAll(f)
+		f.Close()
+		assert.Equal(t, "hello world", string(b))
+	}
+
+	// repeated PATCH to finished upload does not complete it again
+	rec = patch("11", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "11", rec.Header().Get(tusHeaderUploadOffset))
+	rec = patch("11", "more")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 1, completions)
+
+	rec = tusRequest(e, http.MethodDelete, location, "", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = tusRequest(e, http.MethodHead, location, "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTus_options(t *testing.T) {
+	e, _ := newTusTestServer(t, TusConfig{MaxSize: 1024})
+
+	req := httptest.NewRequest(http.MethodOptions, "/files", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, tusVersion, rec.Header().Get(tusHeaderVersion))
+	assert.Equal(t, "creation,creation-with-upload,checksum,termination,expiration", rec.Header().Get(tusHeaderExtension))
+	assert.Equal(t, "1024", rec.Header().Get(tusHeaderMaxSize))
+	assert.Equal(t, "md5,sha1,sha256", rec.Header().Get(tusHeaderChecksumAlgorithm))
+}
+
+func TestTus_creation(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenConfig  TusConfig
+		whenBody     string
+		whenHeaders  map[string]string
+		expectStatus int
+		expectOffset string
+	}{
+		{
+			name:         "ok, creation with upload",
+			whenBody:     "abc",
+			whenHeaders:  map[string]string{tusHeaderUploadLength: "5", echo.HeaderContentType: tusContentType},
+			expectStatus: http.StatusCreated,
+			expectOffset: "3",
+		},
+		{
+			name:         "nok, missing length",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "nok, too large",
+			givenConfig:  TusConfig{MaxSize: 10},
+			whenHeaders:  map[string]string{tusHeaderUploadLength: "11"},
+			expectStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "nok, invalid metadata",
+			whenHeaders:  map[string]string{tusHeaderUploadLength: "1", tusHeaderUploadMetadata: "name !!!"},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "nok, unsupported version",
+			whenHeaders:  map[string]string{tusHeaderUploadLength: "1", tusHeaderResumable: "0.2.2"},
+			expectStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name: "nok, rejected by OnCreate",
+			givenConfig: TusConfig{OnCreate: func(c echo.Context, upload *TusUpload) error {
+				return echo.ErrForbidden
+			}},
+			whenHeaders:  map[string]string{tusHeaderUploadLength: "1"},
+			expectStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, _ := newTusTestServer(t, tc.givenConfig)
+
+			rec := tusRequest(e, http.MethodPost, "/files/", tc.whenBody, tc.whenHeaders)
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.Equal(t, tc.expectOffset, rec.Header().Get(tusHeaderUploadOffset))
+		})
+	}
+}
+
+func TestTus_checksum(t *testing.T) {
+	e, store := newTusTestServer(t, TusConfig{})
+	rec := tusRequest(e, http.MethodPost, "/files", "", map[string]string{tusHeaderUploadLength: "10"})
+	location := rec.Header().Get(echo.HeaderLocation)
+
+	sum := sha1.Sum([]byte("hello"))
+	checksum := "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+
+	var testCases = []struct {
+		name         string
+		whenBody     string
+		whenChecksum string
+		expectStatus int
+		expectOffset int64
+	}{
+		{
+			name:         "nok, checksum mismatch",
+			whenBody:     "hellO",
+			whenChecksum: checksum,
+			expectStatus: tusStatusChecksumMismatch,
+		},
+		{
+			name:         "nok, unsupported algorithm",
+			whenBody:     "hello",
+			whenChecksum: "crc32 AAAA",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "ok, checksum matches",
+			whenBody:     "hello",
+			whenChecksum: checksum,
+			expectStatus: http.StatusNoContent,
+			expectOffset: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := tusRequest(e, http.MethodPatch, location, tc.whenBody, map[string]string{
+				echo.HeaderContentType:  tusContentType,
+				tusHeaderUploadOffset:   "0",
+				tusHeaderUploadChecksum: tc.whenChecksum,
+			})
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			upload, err := store.Get(strings.TrimPrefix(location, "/files/"))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOffset, upload.Offset)
+		})
+	}
+}
+
+func TestTus_patchErrors(t *testing.T) {
+	e, _ := newTusTestServer(t, TusConfig{})
+	rec := tusRequest(e, http.MethodPost, "/files", "", map[string]string{tusHeaderUploadLength: "10"})
+	location := rec.Header().Get(echo.HeaderLocation)
+
+	rec = tusRequest(e, http.MethodPatch, location, "abc", map[string]string{tusHeaderUploadOffset: "0"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = tusRequest(e, http.MethodPatch, location, "abc", map[string]string{
+		echo.HeaderContentType: tusContentType,
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = tusRequest(e, http.MethodPatch, "/files/0123456789abcdef0123456789abcdef", "abc", map[string]string{
+		echo.HeaderContentType: tusContentType,
+		tusHeaderUploadOffset:  "0",
+	})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = tusRequest(e, http.MethodHead, "/files/../../etc", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTus_expiration(t *testing.T) {
+	current := testResponseCacheNow(t)
+	e, store := newTusTestServer(t, TusConfig{Expiration: time.Hour})
+	rec := tusRequest(e, http.MethodPost, "/files", "", map[string]string{tusHeaderUploadLength: "10"})
+	expired := rec.Header().Get(echo.HeaderLocation)
+	rec = tusRequest(e, http.MethodPost, "/files", "", map[string]string{tusHeaderUploadLength: "10"})
+	cleaned := rec.Header().Get(echo.HeaderLocation)
+
+	*current = current.Add(2 * time.Hour)
+	rec = tusRequest(e, http.MethodHead, expired, "", nil)
+	assert.Equal(t, http.StatusGone, rec.Code)
+	rec = tusRequest(e, http.MethodHead, expired, "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	assert.NoError(t, store.Cleanup())
+	_, err := store.Get(strings.TrimPrefix(cleaned, "/files/"))
+	assert.True(t, errors.Is(err, ErrTusUploadNotFound))
+}
+
+func TestMountTus_panicsWithoutStore(t *testing.T) {
+	assert.Panics(t, func() {
+		MountTus(echo.New().Group("/files"), TusConfig{})
+	})
+}
@@ -0,0 +1,365 @@
+package echo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type (
+	// MultipartConfig defines the config for `Context#StreamMultipart`.
+	MultipartConfig struct {
+		// Sink stores content of file parts.
+		// Optional. Default value is a `MultipartDirSink` storing files in `os.TempDir()`.
+		Sink MultipartSink
+
+		// MaxFileSize is the maximum size of a single file in bytes.
+		// Optional. Default value 32 MB.
+		MaxFileSize int64
+
+		// MaxTotalSize is the maximum size of the whole request body in bytes.
+		// Optional. Default value 64 MB.
+		MaxTotalSize int64
+
+		// MaxFieldSize is the maximum size of a single non-file field value in bytes.
+		// Optional. Default value 1 MB.
+		MaxFieldSize int64
+
+		// MaxParts is the maximum number of parts (fields and files) in the form.
+		// Optional. Default value 1000.
+		MaxParts int
+
+		// AllowedTypes is the list of allowed media types of files. Types are compared against the type sniffed
+		// from the file content with `http.DetectContentType` so the type declared by the client can not be used to
+		// bypass the check. Wildcard subtype (`image/*`) matches any subtype. Note that textual formats like JSON
+		// or CSV are sniffed as `text/plain`.
+		// Optional. Default value nil (all types are allowed).
+		AllowedTypes []string
+
+		// OnFile is called for each file part before it is passed to the sink. Returning `ErrMultipartSkip` skips
+		// the file, any other error aborts reading of the form.
+		// Optional.
+		OnFile func(file *MultipartFile) error
+
+		// OnProgress is called every time a chunk of file content is read.
+		// Optional.
+		OnProgress func(progress MultipartProgress)
+	}
+
+	// MultipartSink stores content of multipart file parts.
+	MultipartSink interface {
+		// Store consumes content of the file. Store must release any partially stored content when it returns an
+		// error. Content returns an error when file exceeds `MultipartConfig.MaxFileSize`.
+		Store(file *MultipartFile, content io.Reader) error
+
+		// Remove removes previously stored file.
+		Remove(file *MultipartFile) error
+	}
+
+	// MultipartFile describes a file part of the multipart form.
+	MultipartFile struct {
+		// FieldName is the name of the form field.
+		FieldName string
+		// Filename is the base name of the file as sent by the client.
+		Filename string
+		// Header is the MIME header of the part.
+		Header textproto.MIMEHeader
+		// ContentType is the media type sniffed from the file content.
+		ContentType string
+		// Size is the number of bytes stored. It is set after the sink has stored the file.
+		Size int64
+		// Location is the path of the file stored by `MultipartDirSink`.
+		Location string
+		// Content is the content of the file stored by `MultipartMemorySink`.
+		Content []byte
+	}
+
+	// MultipartProgress describes the progress of reading a multipart form.
+	MultipartProgress struct {
+		// File is the file being read.
+		File *MultipartFile
+		// FileBytes is the number of bytes of the current file read so far.
+		FileBytes int64
+		// TotalBytes is the number of bytes of the request body read so far.
+		TotalBytes int64
+		// ContentLength is the length of the request body or -1 if it is unknown.
+		ContentLength int64
+	}
+
+	// MultipartUpload is the result of `Context#StreamMultipart`.
+	MultipartUpload struct {
+		// Values contains non-file fields of the form.
+		Values url.Values
+		// Files contains stored files in the order they were sent.
+		Files []*MultipartFile
+
+		sink MultipartSink
+	}
+
+	// MultipartDirSink stores files in a directory under random names.
+	MultipartDirSink struct {
+		// Dir is the directory files are stored in.
+		Dir string
+	}
+
+	// MultipartMemorySink stores files in `MultipartFile.Content`.
+	MultipartMemorySink struct{}
+
+	limitedCountingReader struct {
+		r        io.Reader
+		limit    int64
+		n        int64
+		exceeded bool
+		onRead   func()
+	}
+)
+
+const (
+	multipartSniffLen = 512
+)
+
+var (
+	// DefaultMultipartConfig is the default config for `Context#StreamMultipart`.
+	DefaultMultipartConfig = MultipartConfig{
+		MaxFileSize:  32 << 20,
+		MaxTotalSize: 64 << 20,
+		MaxFieldSize: 1 << 20,
+		MaxParts:     1000,
+	}
+
+	// ErrMultipartSkip is returned by `MultipartConfig.OnFile` to skip the file.
+	ErrMultipartSkip = errors.New("skip multipart file")
+
+	errMultipartLimitExceeded = errors.New("multipart limit exceeded")
+)
+
+func (c *context) StreamMultipart(config MultipartConfig) (*MultipartUpload, error) {
+	if config.Sink == nil {
+		config.Sink = &MultipartDirSink{Dir: os.TempDir()}
+	}
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = DefaultMultipartConfig.MaxFileSize
+	}
+	if config.MaxTotalSize == 0 {
+		config.MaxTotalSize = DefaultMultipartConfig.MaxTotalSize
+	}
+	if config.MaxFieldSize == 0 {
+		config.MaxFieldSize = DefaultMultipartConfig.MaxFieldSize
+	}
+	if config.MaxParts == 0 {
+		config.MaxParts = DefaultMultipartConfig.MaxParts
+	}
+
+	mediaType, params, err := mime.ParseMediaType(c.request.Header.Get(HeaderContentType))
+	if err != nil || mediaType != MIMEMultipartForm || params["boundary"] == "" {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	body := &limitedCountingReader{r: c.request.Body, limit: config.MaxTotalSize}
+	reader := multipart.NewReader(body, params["boundary"])
+	upload := &MultipartUpload{Values: url.Values{}, sink: config.Sink}
+	for parts := 0; ; parts++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return upload, nil
+		}
+		if err == nil && parts >= config.MaxParts {
+			err = NewHTTPError(http.StatusRequestEntityTooLarge, "multipart form has too many parts")
+		}
+		if err == nil {
+			if part.FileName() == "" {
+				err = readMultipartValue(upload, part, config.MaxFieldSize)
+			} else {
+				err = readMultipartFile(c, upload, part, body, config)
+			}
+		}
+		if err != nil {
+			upload.RemoveAll()
+			if body.exceeded {
+				return nil, NewHTTPError(http.StatusRequestEntityTooLarge, "multipart form exceeds size limit").SetInternal(err)
+			}
+			if _, ok := err.(*HTTPError); ok {
+				return nil, err
+			}
+			return nil, NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	}
+}
+
+func readMultipartValue(upload *MultipartUpload, part *multipart.Part, limit int64) error {
+	defer part.Close()
+	value, err := ioutil.ReadAll(io.LimitReader(part, limit+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(value)) > limit {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, "multipart field exceeds size limit")
+	}
+	upload.Values.Add(part.FormName(), string(value))
+	return nil
+}
+
+func readMultipartFile(c *context, upload *MultipartUpload, part *multipart.Part, body *limitedCountingReader, config MultipartConfig) error {
+	defer part.Close()
+	file := &MultipartFile{
+		FieldName: part.FormName(),
+		Filename:  part.FileName(),
+		Header:    part.Header,
+	}
+
+	head := make([]byte, multipartSniffLen)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	head = head[:n]
+	file.ContentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	if !isAllowedMediaType(file.ContentType, config.AllowedTypes) {
+		return NewHTTPError(http.StatusUnsupportedMediaType, "multipart file type is not allowed: "+file.ContentType)
+	}
+
+	if config.OnFile != nil {
+		if err := config.OnFile(file); err == ErrMultipartSkip {
+			_, err = io.Copy(ioutil.Discard, part)
+			return err
+		} else if err != nil {
+			return err
+		}
+	}
+
+	content := &limitedCountingReader{r: io.MultiReader(bytes.NewReader(head), part), limit: config.MaxFileSize}
+	if config.OnProgress != nil {
+		progress := MultipartProgress{File: file, ContentLength: c.request.ContentLength}
+		content.onRead = func() {
+			progress.FileBytes = content.n
+			progress.TotalBytes = body.n
+			config.OnProgress(progress)
+		}
+	}
+	if err := config.Sink.Store(file, content); err != nil {
+		if content.exceeded {
+			return NewHTTPError(http.StatusRequestEntityTooLarge, "multipart file exceeds size limit").SetInternal(err)
+		}
+		return err
+	}
+	file.Size = content.n
+	upload.Files = append(upload.Files, file)
+	return nil
+}
+
+func isAllowedMediaType(mediaType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.HasSuffix(a, "/*") {
+			if strings.HasPrefix(mediaType, a[:len(a)-1]) {
+				return true
+			}
+		} else if strings.EqualFold(a, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// File returns the first file stored for the field name or nil.
+func (u *MultipartUpload) File(fieldName string) *MultipartFile {
+	for _, f := range u.Files {
+		if f.FieldName == fieldName {
+			return f
+		}
+	}
+	return nil
+}
+
+// RemoveAll removes all stored files from the sink. It returns the first error encountered.
+func (u *MultipartUpload) RemoveAll() error {
+	var err error
+	for _, f := range u.Files {
+		if rErr := u.sink.Remove(f); rErr != nil && err == nil {
+			err = rErr
+		}
+	}
+	u.Files = nil
+	return err
+}
+
+// Store implements `MultipartSink.Store`. File is stored with random name keeping the extension of the client
+// provided name.
+func (s *MultipartDirSink) Store(file *MultipartFile, content io.Reader) error {
+	f, err := ioutil.TempFile(s.Dir, "upload-*"+filepath.Ext(file.Filename))
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, content); err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	file.Location = f.Name()
+	return nil
+}
+
+// Remove implements `MultipartSink.Remove`.
+func (s *MultipartDirSink) Remove(file *MultipartFile) error {
+	if file.Location == "" {
+		return nil
+	}
+	if err := os.Remove(file.Location); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	file.Location = ""
+	return nil
+}
+
+// Store implements `MultipartSink.Store`.
+func (MultipartMemorySink) Store(file *MultipartFile, content io.Reader) error {
+	b, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	file.Content = b
+	return nil
+}
+
+// Remove implements `MultipartSink.Remove`.
+func (MultipartMemorySink) Remove(file *MultipartFile) error {
+	file.Content = nil
+	return nil
+}
+
+func (r *limitedCountingReader) Read(p []byte) (int, error) {
+	if r.n >= r.limit {
+		// probe for more data to distinguish content of exactly limit bytes from larger one
+		var b [1]byte
+		n, err := r.r.Read(b[:])
+		if n > 0 {
+			r.exceeded = true
+			return 0, errMultipartLimitExceeded
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.limit-r.n {
+		p = p[:r.limit-r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if n > 0 && r.onRead != nil {
+		r.onRead()
+	}
+	return n, err
+}
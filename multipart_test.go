@@ -0,0 +1,253 @@
+package echo
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMultipartPart struct {
+	field    string
+	filename string
+	content  string
+}
+
+var testPNG = "\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("x", 100)
+
+func newMultipartContext(t *testing.T, parts []testMultipartPart) (Context, *httptest.ResponseRecorder) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for _, p := range parts {
+		if p.filename == "" {
+			assert.NoError(t, mw.WriteField(p.field, p.content))
+			continue
+		}
+		w, err := mw.CreateFormFile(p.field, p.filename)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(p.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(HeaderContentType, mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	return New().NewContext(req, rec), rec
+}
+
+func TestContext_StreamMultipart(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		givenConfig   MultipartConfig
+		whenParts     []testMultipartPart
+		expectValues  map[string][]string
+		expectFiles   map[string]string
+		expectTypes   []string
+		expectError   string
+		expectRemoved bool
+	}{
+		{
+			name: "ok, fields and files",
+			whenParts: []testMultipartPart{
+				{field: "name", content: "Jon"},
+				{field: "doc", filename: "a.txt", content: "hello"},
+				{field: "image", filename: "../../b.png", content: testPNG},
+				{field: "name", content: "Snow"},
+			},
+			expectValues: map[string][]string{"name": {"Jon", "Snow"}},
+			expectFiles:  map[string]string{"a.txt": "hello", "b.png": testPNG},
+			expectTypes:  []string{"text/plain", "image/png"},
+		},
+		{
+			name:         "ok, allowed type with wildcard",
+			givenConfig:  MultipartConfig{AllowedTypes: []string{"image/*"}},
+			whenParts:    []testMultipartPart{{field: "image", filename: "b.png", content: testPNG}},
+			expectValues: map[string][]string{},
+			expectFiles:  map[string]string{"b.png": testPNG},
+			expectTypes:  []string{"image/png"},
+		},
+		{
+			name:        "nok, type is sniffed from content",
+			givenConfig: MultipartConfig{AllowedTypes: []string{"image/png"}},
+			whenParts: []testMultipartPart{
+				{field: "image", filename: "b.png", content: testPNG},
+				{field: "image", filename: "fake.png", content: "<html><script></script></html>"},
+			},
+			expectError:   "code=415, message=multipart file type is not allowed: text/html",
+			expectRemoved: true,
+		},
+		{
+			name:         "ok, file of exactly max size",
+			givenConfig:  MultipartConfig{MaxFileSize: 5},
+			whenParts:    []testMultipartPart{{field: "doc", filename: "a.txt", content: "hello"}},
+			expectValues: map[string][]string{},
+			expectFiles:  map[string]string{"a.txt": "hello"},
+			expectTypes:  []string{"text/plain"},
+		},
+		{
+			name:        "nok, file exceeds max size",
+			givenConfig: MultipartConfig{MaxFileSize: 4},
+			whenParts:   []testMultipartPart{{field: "doc", filename: "a.txt", content: "hello"}},
+			expectError: "code=413, message=multipart file exceeds size limit, internal=multipart limit exceeded",
+		},
+		{
+			name:        "nok, body exceeds max total size",
+			givenConfig: MultipartConfig{MaxTotalSize: 400},
+			whenParts: []testMultipartPart{
+				{field: "image", filename: "b.png", content: testPNG},
+				{field: "image", filename: "c.png", content: testPNG},
+			},
+			expectError:   "code=413, message=multipart form exceeds size limit",
+			expectRemoved: true,
+		},
+		{
+			name:        "nok, field exceeds max size",
+			givenConfig: MultipartConfig{MaxFieldSize: 2},
+			whenParts:   []testMultipartPart{{field: "name", content: "Jon"}},
+			expectError: "code=413, message=multipart field exceeds size limit",
+		},
+		{
+			name:        "nok, too many parts",
+			givenConfig: MultipartConfig{MaxParts: 1},
+			whenParts:   []testMultipartPart{{field: "a", content: "1"}, {field: "b", content: "2"}},
+			expectError: "code=413, message=multipart form has too many parts",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newMultipartContext(t, tc.whenParts)
+			var stored []*MultipartFile
+			tc.givenConfig.Sink = &testRecordingSink{stored: &stored}
+
+			upload, err := c.StreamMultipart(tc.givenConfig)
+
+			if tc.expectError != "" {
+				assert.Nil(t, upload)
+				if assert.Error(t, err) {
+					assert.True(t, strings.HasPrefix(err.Error(), tc.expectError), err.Error())
+				}
+				for _, f := range stored {
+					assert.Nil(t, f.Content)
+				}
+				if tc.expectRemoved {
+					assert.NotEmpty(t, stored)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectValues, map[string][]string(upload.Values))
+			files := map[string]string{}
+			types := []string{}
+			for _, f := range upload.Files {
+				files[f.Filename] = string(f.Content)
+				types = append(types, f.ContentType)
+				assert.Equal(t, int64(len(f.Content)), f.Size)
+			}
+			assert.Equal(t, tc.expectFiles, files)
+			assert.Equal(t, tc.expectTypes, types)
+		})
+	}
+}
+
+type testRecordingSink struct {
+	MultipartMemorySink
+	stored *[]*MultipartFile
+}
+
+func (s *testRecordingSink) Store(file *MultipartFile, content io.Reader) error {
+	if err := s.MultipartMemorySink.Store(file, content); err != nil {
+		return err
+	}
+	*s.stored = append(*s.stored, file)
+	return nil
+}
+
+func TestContext_StreamMultipart_skipAndProgress(t *testing.T) {
+	c, _ := newMultipartContext(t, []testMultipartPart{
+		{field: "skip", filename: "a.txt", content: "skipped"},
+		{field: "doc", filename: "b.txt", content: strings.Repeat("x", 10000)},
+	})
+
+	var progress []MultipartProgress
+	upload, err := c.StreamMultipart(MultipartConfig{
+		Sink: MultipartMemorySink{},
+		OnFile: func(file *MultipartFile) error {
+			if file.FieldName == "skip" {
+				return ErrMultipartSkip
+			}
+			return nil
+		},
+		OnProgress: func(p MultipartProgress) {
+			progress = append(progress, p)
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, upload.Files, 1)
+	assert.Nil(t, upload.File("skip"))
+	assert.Equal(t, int64(10000), upload.File("doc").Size)
+	if assert.NotEmpty(t, progress) {
+		last := progress[len(progress)-1]
+		assert.Equal(t, "b.txt", last.File.Filename)
+		assert.Equal(t, int64(10000), last.FileBytes)
+		assert.True(t, last.TotalBytes > 10000)
+		assert.Equal(t, c.Request().ContentLength, last.ContentLength)
+	}
+}
+
+func TestContext_StreamMultipart_dirSink(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := newMultipartContext(t, []testMultipartPart{
+		{field: "doc", filename: "../a.txt", content: "hello"},
+	})
+
+	upload, err := c.StreamMultipart(MultipartConfig{Sink: &MultipartDirSink{Dir: dir}})
+	assert.NoError(t, err)
+
+	file := upload.File("doc")
+	if assert.NotNil(t, file) {
+		assert.True(t, strings.HasPrefix(file.Location, dir))
+		assert.True(t, strings.HasSuffix(file.Location, ".txt"))
+		b, err := ioutil.ReadFile(file.Location)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(b))
+	}
+
+	assert.NoError(t, upload.RemoveAll())
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestContext_StreamMultipart_dirSinkRemovesPartialFile(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := newMultipartContext(t, []testMultipartPart{
+		{field: "doc", filename: "a.txt", content: "hello"},
+		{field: "doc", filename: "b.txt", content: "hello world"},
+	})
+
+	_, err := c.StreamMultipart(MultipartConfig{Sink: &MultipartDirSink{Dir: dir}, MaxFileSize: 5})
+	assert.Error(t, err)
+
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestContext_StreamMultipart_notMultipart(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a=b"))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	c := New().NewContext(req, httptest.NewRecorder())
+
+	_, err := c.StreamMultipart(MultipartConfig{})
+
+	assert.Equal(t, ErrUnsupportedMediaType, err)
+}
@@ -0,0 +1,203 @@
+//go:build go1.16
+// +build go1.16
+
+package echo
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+type (
+	// TemplateRendererConfig defines the config for TemplateRenderer.
+	TemplateRendererConfig struct {
+		// Filesystem templates are loaded from.
+		// Required.
+		//
+		// When dealing with `embed.FS` use `fs := echo.MustSubFS(fs, "rootDirectory") to create sub fs which uses
+		// necessary prefix for directory path.
+		Filesystem fs.FS
+
+		// Extension of template files. Files with other extensions are ignored.
+		// Optional. Default value ".html".
+		Extension string
+
+		// LayoutsDir is the directory of layout templates. Layouts are available to all pages as `layouts/<name>`.
+		// Optional. Default value "layouts".
+		LayoutsDir string
+
+		// PartialsDir is the directory of partial templates. Partials are available to all pages as
+		// `partials/<name>`, e.g. `{{ template "partials/nav" . }}`.
+		// Optional. Default value "partials".
+		PartialsDir string
+
+		// Layout is the name of the layout pages are rendered with. Page defines blocks (e.g.
+		// `{{ define "content" }}...{{ end }}`) used by the layout. When the layout does not exist pages are rendered
+		// without layout.
+		// Optional. Default value "base" (file `layouts/base.html`).
+		Layout string
+
+		// Funcs are added to templates. Function `reverse` generating URL for the named route with `Echo#Reverse()`
+		// is always available, e.g. `{{ reverse "user" .ID }}`. Function `reverseURL` uses `Echo#ReverseURL()` with
+		// params given as key value pairs where keys starting with `?` are added to query string, e.g.
+		// `{{ reverseURL "user" "id" .ID "?tab" "posts" }}`. Errors (e.g. missing param) fail the rendering.
+		// Optional.
+		Funcs template.FuncMap
+	}
+
+	// TemplateRenderer is Renderer implementation with html/template loading templates from fs.FS.
+	//
+	// All files outside of layouts and partials directories are pages and are rendered by their path without
+	// extension, e.g. `c.Render(http.StatusOK, "users/show", data)` renders `users/show.html` with the layout. Layouts
+	// and partials can be rendered by their names too, e.g. `c.Render(http.StatusOK, "partials/row", data)`.
+	//
+	// Templates are parsed once when renderer is created so errors are reported at startup. When `Echo#Debug` is
+	// enabled templates are parsed again for every render so changes are visible without restart.
+	TemplateRenderer struct {
+		config    TemplateRendererConfig
+		funcs     template.FuncMap
+		templates *templateSet
+	}
+
+	templateSet struct {
+		// base holds layouts and partials
+		base  *template.Template
+		pages map[string]*template.Template
+	}
+)
+
+// DefaultTemplateRendererConfig is the default TemplateRenderer config.
+var DefaultTemplateRendererConfig = TemplateRendererConfig{
+	Extension:   ".html",
+	LayoutsDir:  "layouts",
+	PartialsDir: "partials",
+	Layout:      "base",
+}
+
+// NewTemplateRenderer creates TemplateRenderer and parses all templates. Echo instance is used by the `reverse`
+// template function.
+//
+// Example:
+//
+//	e.Renderer = echo.MustTemplateRenderer(e, echo.TemplateRendererConfig{Filesystem: echo.MustSubFS(views, "views")})
+func NewTemplateRenderer(e *Echo, config TemplateRendererConfig) (*TemplateRenderer, error) {
+	if config.Filesystem == nil {
+		return nil, fmt.Errorf("template renderer requires filesystem")
+	}
+	if config.Extension == "" {
+		config.Extension = DefaultTemplateRendererConfig.Extension
+	}
+	if config.LayoutsDir == "" {
+		config.LayoutsDir = DefaultTemplateRendererConfig.LayoutsDir
+	}
+	if config.PartialsDir == "" {
+		config.PartialsDir = DefaultTemplateRendererConfig.PartialsDir
+	}
+	if config.Layout == "" {
+		config.Layout = DefaultTemplateRendererConfig.Layout
+	}
+
+	funcs := template.FuncMap{
+		"reverse":    e.Reverse,
+		"reverseURL": e.reverseURLPairs,
+	}
+	for name, f := range config.Funcs {
+		funcs[name] = f
+	}
+
+	r := &TemplateRenderer{config: config, funcs: funcs}
+	templates, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+	r.templates = templates
+	return r, nil
+}
+
+// MustTemplateRenderer creates TemplateRenderer or panics on failure.
+// See: `NewTemplateRenderer()`.
+func MustTemplateRenderer(e *Echo, config TemplateRendererConfig) *TemplateRenderer {
+	r, err := NewTemplateRenderer(e, config)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Render renders template with given name. Implements Renderer interface.
+func (r *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	templates := r.templates
+	if c != nil && c.Echo().Debug {
+		var err error
+		if templates, err = r.parse(); err != nil {
+			return err
+		}
+	}
+
+	if page, ok := templates.pages[name]; ok {
+		layout := path.Join(r.config.LayoutsDir, r.config.Layout)
+		if page.Lookup(layout) != nil {
+			return page.ExecuteTemplate(w, layout, data)
+		}
+		return page.ExecuteTemplate(w, name, data)
+	}
+	if templates.base.Lookup(name) != nil {
+		return templates.base.ExecuteTemplate(w, name, data)
+	}
+	return fmt.Errorf("template renderer: template %q not found", name)
+}
+
+func (r *TemplateRenderer) parse() (*templateSet, error) {
+	base := template.New("").Funcs(r.funcs)
+	var pages []string
+
+	err := fs.WalkDir(r.config.Filesystem, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(file) != r.config.Extension {
+			return nil
+		}
+		if !isInDir(file, r.config.LayoutsDir) && !isInDir(file, r.config.PartialsDir) {
+			pages = append(pages, file)
+			return nil
+		}
+		return r.parseFile(base, file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	templates := &templateSet{base: base, pages: make(map[string]*template.Template, len(pages))}
+	for _, file := range pages {
+		page, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.parseFile(page, file); err != nil {
+			return nil, err
+		}
+		templates.pages[strings.TrimSuffix(file, r.config.Extension)] = page
+	}
+	return templates, nil
+}
+
+func (r *TemplateRenderer) parseFile(t *template.Template, file string) error {
+	b, err := fs.ReadFile(r.config.Filesystem, file)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(file, r.config.Extension)
+	if _, err := t.New(name).Parse(string(b)); err != nil {
+		return fmt.Errorf("template renderer: failed to parse %s: %w", file, err)
+	}
+	return nil
+}
+
+func isInDir(file, dir string) bool {
+	return strings.HasPrefix(file, dir+"/")
+}
@@ -0,0 +1,160 @@
+//go:build go1.16
+// +build go1.16
+
+package echo
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTemplatesFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":  &fstest.MapFile{Data: []byte(`<title>{{ block "title" . }}Site{{ end }}</title>{{ template "partials/nav" . }}<main>{{ block "content" . }}{{ end }}</main>`)},
+		"partials/nav.html":  &fstest.MapFile{Data: []byte(`<nav><a href="{{ reverse "user" 42 }}">me</a></nav>`)},
+		"partials/row.html":  &fstest.MapFile{Data: []byte(`<tr>{{ upper .Name }}</tr>`)},
+		"users/show.html":    &fstest.MapFile{Data: []byte(`{{ define "title" }}User {{ .Name }}{{ end }}{{ define "content" }}<p>{{ .Name }}</p>{{ end }}`)},
+		"index.html":         &fstest.MapFile{Data: []byte(`{{ define "content" }}home{{ end }}`)},
+		"styles/site.css":    &fstest.MapFile{Data: []byte(`body {}`)},
+		"partials/README.md": &fstest.MapFile{Data: []byte(`{{ invalid`)},
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", func(c Context) error { return nil }).Name = "user"
+	r, err := NewTemplateRenderer(e, TemplateRendererConfig{
+		Filesystem: testTemplatesFS(),
+		Funcs:      template.FuncMap{"upper": strings.ToUpper},
+	})
+	assert.NoError(t, err)
+	e.Renderer = r
+
+	var testCases = []struct {
+		name         string
+		whenTemplate string
+		expect       string
+		expectErr    string
+	}{
+		{
+			name:         "ok, page with layout",
+			whenTemplate: "users/show",
+			expect:       `<title>User &lt;Jon&gt;</title><nav><a href="/users/42">me</a></nav><main><p>&lt;Jon&gt;</p></main>`,
+		},
+		{
+			name:         "ok, page with default block",
+			whenTemplate: "index",
+			expect:       `<title>Site</title><nav><a href="/users/42">me</a></nav><main>home</main>`,
+		},
+		{
+			name:         "ok, partial",
+			whenTemplate: "partials/row",
+			expect:       `<tr>&lt;JON&gt;</tr>`,
+		},
+		{
+			name:         "nok, unknown template",
+			whenTemplate: "missing",
+			expectErr:    `template renderer: template "missing" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+			err := c.Render(http.StatusOK, tc.whenTemplate, map[string]string{"Name": "<Jon>"})
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, rec.Body.String())
+		})
+	}
+}
+
+func TestTemplateRenderer_withoutLayout(t *testing.T) {
+	e := New()
+	r := MustTemplateRenderer(e, TemplateRendererConfig{
+		Filesystem: fstest.MapFS{
+			"hello.tmpl": &fstest.MapFile{Data: []byte(`Hello {{ . }}`)},
+		},
+		Extension: ".tmpl",
+	})
+
+	buf := new(strings.Builder)
+	assert.NoError(t, r.Render(buf, "hello", "World", nil))
+	assert.Equal(t, "Hello World", buf.String())
+}
+
+func TestTemplateRenderer_errorsAtStartup(t *testing.T) {
+	e := New()
+	_, err := NewTemplateRenderer(e, TemplateRendererConfig{
+		Filesystem: fstest.MapFS{
+			"broken.html": &fstest.MapFile{Data: []byte(`{{ .Name `)},
+		},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse broken.html")
+
+	_, err = NewTemplateRenderer(e, TemplateRendererConfig{
+		Filesystem: fstest.MapFS{
+			"unknown.html": &fstest.MapFile{Data: []byte(`{{ missingFunc }}`)},
+		},
+	})
+	assert.Error(t, err)
+
+	_, err = NewTemplateRenderer(e, TemplateRendererConfig{})
+	assert.EqualError(t, err, "template renderer requires filesystem")
+
+	assert.Panics(t, func() {
+		MustTemplateRenderer(e, TemplateRendererConfig{})
+	})
+}
+
+func TestTemplateRenderer_reloadInDebug(t *testing.T) {
+	filesystem := fstest.MapFS{
+		"page.html": &fstest.MapFile{Data: []byte(`v1`)},
+	}
+	e := New()
+	e.Renderer = MustTemplateRenderer(e, TemplateRendererConfig{Filesystem: filesystem})
+	render := func() string {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		assert.NoError(t, c.Render(http.StatusOK, "page", nil))
+		return rec.Body.String()
+	}
+
+	filesystem["page.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+	assert.Equal(t, "v1", render())
+
+	e.Debug = true
+	assert.Equal(t, "v2", render())
+}
+
+func TestTemplateRenderer_reverseURL(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", func(c Context) error { return nil }).Name = "user"
+	e.Renderer = MustTemplateRenderer(e, TemplateRendererConfig{
+		Filesystem: fstest.MapFS{
+			"link.html":    &fstest.MapFile{Data: []byte(`<a href="{{ reverseURL "user" "id" .ID "?tab" "posts" }}">me</a>`)},
+			"missing.html": &fstest.MapFile{Data: []byte(`{{ reverseURL "user" }}`)},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.NoError(t, c.Render(http.StatusOK, "link", map[string]string{"ID": "a b"}))
+	assert.Equal(t, `<a href="/users/a%20b?tab=posts">me</a>`, rec.Body.String())
+
+	err := c.Render(http.StatusOK, "missing", nil)
+	assert.True(t, errors.Is(err, ErrReverseParamMissing))
+}
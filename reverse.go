@@ -0,0 +1,291 @@
+package echo
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReverseURL generates URL for the named route from named params and appends query string from query values.
+//
+// Params can be nil, a map with string keys (e.g. `map[string]interface{}{"id": 1}`) or a struct (or pointer to it).
+// Struct fields tagged with `param:"<name>"` are used as route params and fields tagged with `query:"<name>"` are
+// added to the query string (zero values are skipped). Wildcard route param is named `*` and keeps `/` characters,
+// all other param values are path escaped. Values implementing `encoding.TextMarshaler` are marshaled, other values
+// are formatted with `fmt.Sprint`.
+//
+// Routes registered on a host (see `Echo#Host()`) produce scheme-relative absolute URLs (`//api.example.com/users/1`)
+// so they work with both http and https. Named labels of host patterns (`{tenant}.example.com`) are filled from params
+// too. Host patterns with wildcard labels can not be reversed.
+//
+// Returns error wrapping `ErrRouteNotFound`, `ErrReverseParamMissing` or `ErrReverseParamUnknown` when route does not
+// exist, when route param has no value or when param is not used by the route.
+func (e *Echo) ReverseURL(name string, params interface{}, query url.Values) (string, error) {
+	route, host := e.findRouteByName(name)
+	if route == nil {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+
+	values, structQuery, err := reverseParams(params)
+	if err != nil {
+		return "", err
+	}
+	used := make(map[string]bool, len(values))
+
+	uri := new(strings.Builder)
+	if host != "" {
+		uri.WriteString("//")
+		if err := reverseHost(uri, host, values, used); err != nil {
+			return "", err
+		}
+	}
+	if err := reversePath(uri, route.Path, values, used); err != nil {
+		return "", err
+	}
+
+	if len(used) != len(values) {
+		unknown := make([]string, 0, len(values)-len(used))
+		for name := range values {
+			if !used[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		return "", fmt.Errorf("%w: %q", ErrReverseParamUnknown, strings.Join(unknown, ", "))
+	}
+
+	for k, v := range query {
+		structQuery[k] = append(structQuery[k], v...)
+	}
+	if len(structQuery) > 0 {
+		uri.WriteByte('?')
+		uri.WriteString(structQuery.Encode())
+	}
+	return uri.String(), nil
+}
+
+// reverseURLPairs is `ReverseURL()` variant for templates taking params as key value pairs, e.g.
+// `{{ reverseURL "user" "id" .ID "?tab" "posts" }}`. Keys starting with `?` are added to the query string.
+func (e *Echo) reverseURLPairs(name string, pairs ...interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("reverseURL: odd number of key value arguments for route %q", name)
+	}
+	params := make(map[string]interface{}, len(pairs)/2)
+	query := url.Values{}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("reverseURL: key must be string, got %T", pairs[i])
+		}
+		if strings.HasPrefix(key, "?") {
+			value, err := reverseValue(reflect.ValueOf(pairs[i+1]))
+			if err != nil {
+				return "", err
+			}
+			query.Add(key[1:], value)
+			continue
+		}
+		params[key] = pairs[i+1]
+	}
+	return e.ReverseURL(name, params, query)
+}
+
+// findRouteByName returns route with given name and the host it is registered on. When multiple routes have the same
+// name, routes without host win and then the route with the lowest host, path and method, so result does not depend
+// on map iteration order.
+func (e *Echo) findRouteByName(name string) (*Route, string) {
+	hosts := make(map[*Route]string)
+	for host, router := range e.routers {
+		for _, r := range router.routes {
+			hosts[r] = host
+		}
+	}
+
+	var found *Route
+	foundHost := ""
+	for _, r := range e.router.routes {
+		if r.Name != name {
+			continue
+		}
+		host := hosts[r]
+		if found == nil || routeLess(r, host, found, foundHost) {
+			found, foundHost = r, host
+		}
+	}
+	return found, foundHost
+}
+
+func routeLess(a *Route, aHost string, b *Route, bHost string) bool {
+	if (aHost == "") != (bHost == "") {
+		return aHost == ""
+	}
+	if aHost != bHost {
+		return aHost < bHost
+	}
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.Method < b.Method
+}
+
+func reversePath(uri *strings.Builder, path string, values map[string]string, used map[string]bool) error {
+	for i, l := 0, len(path); i < l; i++ {
+		switch path[i] {
+		case '\\':
+			// escaped colon is a literal `:` in the path
+			if i+1 < l && path[i+1] == ':' {
+				i++
+			}
+			uri.WriteByte(path[i])
+		case ':':
+			j := i + 1
+			for ; j < l && path[j] != '/'; j++ {
+			}
+			name := path[i+1 : j]
+			value, ok := values[name]
+			if !ok {
+				return fmt.Errorf("%w: %q", ErrReverseParamMissing, name)
+			}
+			used[name] = true
+			uri.WriteString(url.PathEscape(value))
+			i = j - 1
+		case '*':
+			// wildcard matches also empty path so the value is optional
+			if value, ok := values["*"]; ok {
+				used["*"] = true
+				segments := strings.Split(value, "/")
+				for k, s := range segments {
+					segments[k] = url.PathEscape(s)
+				}
+				uri.WriteString(strings.Join(segments, "/"))
+			}
+		default:
+			uri.WriteByte(path[i])
+		}
+	}
+	return nil
+}
+
+func reverseHost(uri *strings.Builder, host string, values map[string]string, used map[string]bool) error {
+	if !isHostPattern(host) {
+		uri.WriteString(host)
+		return nil
+	}
+	for i, label := range strings.Split(host, ".") {
+		if i > 0 {
+			uri.WriteByte('.')
+		}
+		switch {
+		case label == "*":
+			return fmt.Errorf("host pattern with wildcard label can not be reversed: %q", host)
+		case strings.HasPrefix(label, "{"):
+			name := label[1 : len(label)-1]
+			value, ok := values[name]
+			if !ok || value == "" {
+				return fmt.Errorf("%w: %q", ErrReverseParamMissing, name)
+			}
+			used[name] = true
+			uri.WriteString(url.PathEscape(value))
+		default:
+			uri.WriteString(label)
+		}
+	}
+	return nil
+}
+
+// reverseParams converts params given to `ReverseURL()` to route param values and query values.
+func reverseParams(params interface{}) (map[string]string, url.Values, error) {
+	values := map[string]string{}
+	query := url.Values{}
+	if params == nil {
+		return values, query, nil
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return values, query, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, nil, fmt.Errorf("reverse params map must have string keys, got %v", v.Type())
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := reverseValue(iter.Value())
+			if err != nil {
+				return nil, nil, err
+			}
+			values[iter.Key().String()] = value
+		}
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			fv := v.Field(i)
+			if name, _ := parseBindTag(field.Tag.Get("param")); name != "" && name != "-" {
+				value, err := reverseValue(fv)
+				if err != nil {
+					return nil, nil, err
+				}
+				values[name] = value
+				continue
+			}
+			name, _ := parseBindTag(field.Tag.Get("query"))
+			if name == "" || name == "-" || fv.IsZero() {
+				continue
+			}
+			items := []reflect.Value{fv}
+			if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+				items = items[:0]
+				for j := 0; j < fv.Len(); j++ {
+					items = append(items, fv.Index(j))
+				}
+			}
+			for _, item := range items {
+				value, err := reverseValue(item)
+				if err != nil {
+					return nil, nil, err
+				}
+				query.Add(name, value)
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("reverse params must be map or struct, got %T", params)
+	}
+	return values, query, nil
+}
+
+func reverseValue(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", nil
+	}
+	if !v.CanInterface() {
+		return fmt.Sprint(v), nil
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
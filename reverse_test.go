@@ -0,0 +1,164 @@
+package echo
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEchoReverseURL(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return nil }
+	e.GET("/static", h).Name = "static"
+	e.GET("/users/:id", h).Name = "user"
+	e.GET("/users/:id/files/*", h).Name = "user-file"
+	e.GET("/time\\:now", h).Name = "colon"
+	e.Host("api.example.com").GET("/users/:id", h).Name = "api-user"
+	e.Host("{tenant}.example.com").GET("/dashboard", h).Name = "tenant"
+	e.Host("*.example.com").GET("/any", h).Name = "wildcard-host"
+
+	type userParams struct {
+		ID     int      `param:"id"`
+		Tab    string   `query:"tab"`
+		Tags   []string `query:"tag"`
+		Ignore string
+	}
+
+	var testCases = []struct {
+		name        string
+		whenName    string
+		whenParams  interface{}
+		whenQuery   url.Values
+		expect      string
+		expectErrIs error
+		expectErr   string
+	}{
+		{
+			name:     "ok, static route",
+			whenName: "static",
+			expect:   "/static",
+		},
+		{
+			name:       "ok, map params are escaped",
+			whenName:   "user",
+			whenParams: map[string]interface{}{"id": "a/b c"},
+			expect:     "/users/a%2Fb%20c",
+		},
+		{
+			name:       "ok, map of strings",
+			whenName:   "user",
+			whenParams: map[string]string{"id": "1"},
+			whenQuery:  url.Values{"b": {"2"}, "a": {"1 2"}},
+			expect:     "/users/1?a=1+2&b=2",
+		},
+		{
+			name:       "ok, struct params with query fields",
+			whenName:   "user",
+			whenParams: &userParams{ID: 7, Tags: []string{"x", "y"}, Ignore: "ignored"},
+			whenQuery:  url.Values{"page": {"2"}},
+			expect:     "/users/7?page=2&tag=x&tag=y",
+		},
+		{
+			name:       "ok, wildcard keeps slashes",
+			whenName:   "user-file",
+			whenParams: map[string]interface{}{"id": 1, "*": "docs/my file.txt"},
+			expect:     "/users/1/files/docs/my%20file.txt",
+		},
+		{
+			name:       "ok, wildcard is optional",
+			whenName:   "user-file",
+			whenParams: map[string]interface{}{"id": 1},
+			expect:     "/users/1/files/",
+		},
+		{
+			name:       "ok, text marshaler",
+			whenName:   "user",
+			whenParams: map[string]interface{}{"id": net.ParseIP("127.0.0.1")},
+			expect:     "/users/127.0.0.1",
+		},
+		{
+			name:     "ok, escaped colon",
+			whenName: "colon",
+			expect:   "/time:now",
+		},
+		{
+			name:       "ok, host route",
+			whenName:   "api-user",
+			whenParams: map[string]interface{}{"id": 1},
+			expect:     "//api.example.com/users/1",
+		},
+		{
+			name:       "ok, host pattern",
+			whenName:   "tenant",
+			whenParams: map[string]interface{}{"tenant": "acme"},
+			expect:     "//acme.example.com/dashboard",
+		},
+		{
+			name:        "nok, host pattern param missing",
+			whenName:    "tenant",
+			expectErrIs: ErrReverseParamMissing,
+			expectErr:   `missing route param: "tenant"`,
+		},
+		{
+			name:      "nok, wildcard host",
+			whenName:  "wildcard-host",
+			expectErr: `host pattern with wildcard label can not be reversed: "*.example.com"`,
+		},
+		{
+			name:        "nok, unknown route",
+			whenName:    "missing",
+			expectErrIs: ErrRouteNotFound,
+			expectErr:   `route not found: "missing"`,
+		},
+		{
+			name:        "nok, missing param",
+			whenName:    "user",
+			expectErrIs: ErrReverseParamMissing,
+			expectErr:   `missing route param: "id"`,
+		},
+		{
+			name:        "nok, unknown params",
+			whenName:    "user",
+			whenParams:  map[string]interface{}{"id": 1, "name": "x", "age": 2},
+			expectErrIs: ErrReverseParamUnknown,
+			expectErr:   `unknown route param: "age, name"`,
+		},
+		{
+			name:       "nok, invalid params type",
+			whenName:   "user",
+			whenParams: []string{"1"},
+			expectErr:  "reverse params must be map or struct, got []string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uri, err := e.ReverseURL(tc.whenName, tc.whenParams, tc.whenQuery)
+
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				if tc.expectErrIs != nil {
+					assert.True(t, errors.Is(err, tc.expectErrIs))
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, uri)
+		})
+	}
+}
+
+func TestEchoReverseURL_prefersRouteWithoutHost(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return nil }
+	e.Host("api.example.com").GET("/users", h).Name = "users"
+	e.GET("/users", h).Name = "users"
+
+	uri, err := e.ReverseURL("users", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/users", uri)
+	assert.Len(t, e.Routes(), 2)
+}
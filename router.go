@@ -2,7 +2,10 @@ package echo
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 type (
@@ -12,6 +15,41 @@ type (
 		tree   *node
 		routes map[string]*Route
 		echo   *Echo
+
+		// CaseInsensitive defines how request path matching a route only when static path segments are compared
+		// ignoring (ASCII) case is handled, e.g. `/Users/1` for route `/users/:id`. Param values keep their case.
+		// Optional. Default value RouteMatchStrict.
+		CaseInsensitive RouteMatch
+		// TrailingSlash defines how request path matching a route only when trailing slash is added or removed is
+		// handled, e.g. `/users/1/` for route `/users/:id`.
+		// Optional. Default value RouteMatchStrict.
+		TrailingSlash RouteMatch
+		// Strict enables strict registration mode. In strict mode `Add` panics with *RouteError instead of logging
+		// route without handler, overwriting duplicate route or accepting invalid or ambiguous path (see `TryAdd`), so
+		// misconfiguration fails at startup.
+		// Optional. Default value false.
+		Strict bool
+
+		// registered contains registered routes by method and path with param names removed (`GET/users/:`)
+		registered map[string]registeredRoute
+		// paramNames contains names of params by path of the param node (`/users/:`)
+		paramNames map[string]string
+		// parent is the router whose CaseInsensitive, TrailingSlash and Strict options are used instead of own ones.
+		// Routers created by `Echo#Host()` use options of the default router so options can be changed after hosts
+		// are added.
+		parent *Router
+	}
+	registeredRoute struct {
+		path string
+		// placeholder routes (registered by `Group#Use()`) can be replaced by routes registered later in strict mode
+		placeholder bool
+	}
+	// RouteError is the error returned by `Router#TryAdd()` and the panic value of `Router#Add()` in strict mode when
+	// route can not be registered.
+	RouteError struct {
+		Method string
+		Path   string
+		Err    error
 	}
 	node struct {
 		kind           kind
@@ -43,6 +81,7 @@ type (
 		put         HandlerFunc
 		trace       HandlerFunc
 		report      HandlerFunc
+		anyOther    map[string]HandlerFunc
 		allowHeader string
 	}
 )
@@ -56,6 +95,23 @@ const (
 	anyLabel   = byte('*')
 )
 
+// RouteMatch defines how Router handles request path which does not match any route exactly but matches a route
+// loosely (see `Router.CaseInsensitive` and `Router.TrailingSlash`).
+type RouteMatch uint8
+
+const (
+	// RouteMatchStrict does not match loosely matching paths. Request is handled as not found.
+	RouteMatchStrict RouteMatch = iota
+	// RouteMatchInPlace serves loosely matching path with the matched route handler without changing request path.
+	RouteMatchInPlace
+	// RouteMatchMovedPermanently redirects loosely matching path to the canonical registered path with
+	// `301 Moved Permanently` status. Note: clients may change method of the redirected request to GET.
+	RouteMatchMovedPermanently
+	// RouteMatchPermanentRedirect redirects loosely matching path to the canonical registered path with
+	// `308 Permanent Redirect` status keeping the request method and body.
+	RouteMatchPermanentRedirect
+)
+
 func (m *methodHandler) isHandler() bool {
 	return m.connect != nil ||
 		m.delete != nil ||
@@ -67,7 +123,8 @@ func (m *methodHandler) isHandler() bool {
 		m.propfind != nil ||
 		m.put != nil ||
 		m.trace != nil ||
-		m.report != nil
+		m.report != nil ||
+		len(m.anyOther) != 0
 }
 
 func (m *methodHandler) updateAllowHeader() {
@@ -112,22 +169,74 @@ func (m *methodHandler) updateAllowHeader() {
 	if m.report != nil {
 		buf.WriteString(", REPORT")
 	}
+	other := make([]string, 0, len(m.anyOther))
+	for method := range m.anyOther {
+		other = append(other, method)
+	}
+	sort.Strings(other)
+	for _, method := range other {
+		buf.WriteString(", ")
+		buf.WriteString(method)
+	}
 	m.allowHeader = buf.String()
 }
 
 // NewRouter returns a new Router instance.
 func NewRouter(e *Echo) *Router {
-	return &Router{
+	r := &Router{
 		tree: &node{
 			methodHandler: new(methodHandler),
 		},
-		routes: map[string]*Route{},
-		echo:   e,
+		routes:     map[string]*Route{},
+		echo:       e,
+		registered: map[string]registeredRoute{},
+		paramNames: map[string]string{},
+	}
+	return r
+}
+
+// options returns the router whose loose matching and strict mode options apply to this router.
+func (r *Router) options() *Router {
+	if r.parent != nil {
+		return r.parent
 	}
+	return r
 }
 
-// Add registers a new route for method and path with matching handler.
+// Error returns error message.
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("echo: route %s %s: %v", e.Method, e.Path, e.Err)
+}
+
+// Unwrap returns the cause of the error.
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// Add registers a new route for method and path with matching handler. In strict mode (see `Router.Strict`) it
+// panics with *RouteError when route can not be registered.
 func (r *Router) Add(method, path string, h HandlerFunc) {
+	if _, err := r.add(method, path, h, false, r.options().Strict); err != nil {
+		panic(err)
+	}
+}
+
+// TryAdd registers a new route for method and path with matching handler. Regardless of strict mode it returns
+// *RouteError and does not register the route when:
+// - handler is nil (wraps ErrRouteHandlerMissing)
+// - path is invalid, i.e. has empty, invalid or duplicate param names (wraps ErrRouteInvalidPath)
+// - route with the same method and path (ignoring param names) is already registered (wraps ErrRouteDuplicate)
+// - param name differs from the name of param registered at the same position by another route, e.g. `/a/:x` and
+// `/a/:y` (wraps ErrRouteParamConflict)
+func (r *Router) TryAdd(method, path string, h HandlerFunc) error {
+	_, err := r.add(method, path, h, false, true)
+	return err
+}
+
+// add registers the route checking it first when check is true. Returns false when placeholder route was not
+// registered because route with the same method and path already exists. Placeholder routes never replace other
+// routes regardless of check.
+func (r *Router) add(method, path string, h HandlerFunc, placeholder bool, check bool) (bool, error) {
 	// Validate path
 	if path == "" {
 		path = "/"
@@ -135,11 +244,90 @@ func (r *Router) Add(method, path string, h HandlerFunc) {
 	if path[0] != '/' {
 		path = "/" + path
 	}
+
+	key, params, pathErr := parseRoutePath(path)
+	if placeholder {
+		if existing, ok := r.registered[method+key]; ok && !existing.placeholder {
+			return false, nil
+		}
+	}
+	if check {
+		routeErr := func(err error) error {
+			return &RouteError{Method: method, Path: path, Err: err}
+		}
+		if h == nil {
+			return false, routeErr(ErrRouteHandlerMissing)
+		}
+		if pathErr != nil {
+			return false, routeErr(pathErr)
+		}
+		if existing, ok := r.registered[method+key]; ok && !existing.placeholder {
+			return false, routeErr(fmt.Errorf("%w as %s", ErrRouteDuplicate, existing.path))
+		}
+		for _, p := range params {
+			if name, ok := r.paramNames[p.path]; ok && name != p.name {
+				return false, routeErr(fmt.Errorf("%w: %q differs from %q", ErrRouteParamConflict, p.name, name))
+			}
+		}
+	}
+
+	r.insertRoute(method, path, h)
+	r.registered[method+key] = registeredRoute{path: path, placeholder: placeholder}
+	for _, p := range params {
+		if _, ok := r.paramNames[p.path]; !ok {
+			r.paramNames[p.path] = p.name
+		}
+	}
+	return true, nil
+}
+
+type routeParam struct {
+	path string
+	name string
+}
+
+// parseRoutePath returns route path with param names removed and params of the route. Returns error wrapping
+// ErrRouteInvalidPath when param name is empty, invalid or used more than once.
+func parseRoutePath(path string) (string, []routeParam, error) {
+	var params []routeParam
+	key := new(strings.Builder)
+	for i, l := 0, len(path); i < l; i++ {
+		switch {
+		case path[i] == '\\' && i+1 < l && path[i+1] == ':':
+			key.WriteString(`\:`)
+			i++
+		case path[i] == ':':
+			j := i + 1
+			for ; j < l && path[j] != '/'; j++ {
+			}
+			name := path[i+1 : j]
+			if name == "" {
+				return "", nil, fmt.Errorf("%w: empty param name", ErrRouteInvalidPath)
+			}
+			if strings.ContainsAny(name, ":*") {
+				return "", nil, fmt.Errorf("%w: invalid param name %q", ErrRouteInvalidPath, name)
+			}
+			for _, p := range params {
+				if p.name == name {
+					return "", nil, fmt.Errorf("%w: duplicate param name %q", ErrRouteInvalidPath, name)
+				}
+			}
+			key.WriteByte(':')
+			params = append(params, routeParam{path: key.String(), name: name})
+			i = j - 1
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	return key.String(), params, nil
+}
+
+func (r *Router) insertRoute(method, path string, h HandlerFunc) {
 	pnames := []string{} // Param names
 	ppath := path        // Pristine path
 
 	if h == nil && r.echo.Logger != nil {
-		// FIXME: in future we should return error
+		// strict mode (`Router.Strict`) or `Router#TryAdd()` report this as error
 		r.echo.Logger.Errorf("Adding route without handler function: %v:%v", method, path)
 	}
 
@@ -371,6 +559,17 @@ func (n *node) addHandler(method string, h HandlerFunc) {
 		n.methodHandler.trace = h
 	case REPORT:
 		n.methodHandler.report = h
+	default:
+		// methods without a dedicated field (WebDAV, QUERY, custom verbs) are kept in a map so the lookup of
+		// standard methods stays a plain switch
+		if h == nil {
+			delete(n.methodHandler.anyOther, method)
+			break
+		}
+		if n.methodHandler.anyOther == nil {
+			n.methodHandler.anyOther = map[string]HandlerFunc{}
+		}
+		n.methodHandler.anyOther[method] = h
 	}
 
 	n.methodHandler.updateAllowHeader()
@@ -406,7 +605,7 @@ func (n *node) findHandler(method string) HandlerFunc {
 	case REPORT:
 		return n.methodHandler.report
 	default:
-		return nil
+		return n.methodHandler.anyOther[method]
 	}
 }
 
@@ -428,8 +627,21 @@ func optionsMethodHandler(allowMethods string) func(c Context) error {
 // - Get context from `Echo#AcquireContext()`
 // - Reset it `Context#Reset()`
 // - Return it `Echo#ReleaseContext()`.
+//
+// When path does not match any route and `CaseInsensitive` or `TrailingSlash` options are enabled, path is matched
+// loosely and handled in place or redirected to the canonical registered path.
 func (r *Router) Find(method, path string, c Context) {
 	ctx := c.(*context)
+	if r.find(method, path, ctx) {
+		return
+	}
+	if o := r.options(); o.CaseInsensitive != RouteMatchStrict || o.TrailingSlash != RouteMatchStrict {
+		r.findLoose(method, path, ctx, o)
+	}
+}
+
+// find is exact lookup used by Find. Returns false when path did not match any route.
+func (r *Router) find(method, path string, ctx *context) bool {
 	ctx.path = path
 	currentNode := r.tree // Current node as root
 
@@ -508,7 +720,7 @@ func (r *Router) Find(method, path string, c Context) {
 			// No matching prefix, let's backtrack to the first possible alternative node of the decision path
 			nk, ok := backtrackToNextNodeKind(staticKind)
 			if !ok {
-				return // No other possibilities on the decision path
+				return false // No other possibilities on the decision path
 			} else if nk == paramKind {
 				goto Param
 				// NOTE: this case (backtracking from static node to previous any node) can not happen by current any matching logic. Any node is end of search currently
@@ -603,9 +815,10 @@ func (r *Router) Find(method, path string, c Context) {
 	}
 
 	if currentNode == nil && previousBestMatchNode == nil {
-		return // nothing matched at all
+		return false // nothing matched at all
 	}
 
+	found := true
 	if matchedHandler != nil {
 		ctx.handler = matchedHandler
 	} else {
@@ -614,6 +827,7 @@ func (r *Router) Find(method, path string, c Context) {
 		currentNode = previousBestMatchNode
 
 		ctx.handler = NotFoundHandler
+		found = currentNode.isHandler
 		if currentNode.isHandler {
 			ctx.Set(ContextKeyHeaderAllow, currentNode.methodHandler.allowHeader)
 			ctx.handler = MethodNotAllowedHandler
@@ -624,4 +838,157 @@ func (r *Router) Find(method, path string, c Context) {
 	}
 	ctx.path = currentNode.ppath
 	ctx.pnames = currentNode.pnames
+	return found
+}
+
+// findLoose looks up route for path which did not match exactly. Path is matched with static segments compared
+// ignoring case and/or with trailing slash toggled depending on router options o.
+func (r *Router) findLoose(method, path string, ctx *context, o *Router) {
+	var toggled string
+	if o.TrailingSlash != RouteMatchStrict && len(path) > 1 {
+		if path[len(path)-1] == '/' {
+			toggled = path[:len(path)-1]
+		} else {
+			toggled = path + "/"
+		}
+	}
+
+	m := &looseMatch{method: method}
+	var mode RouteMatch
+	switch {
+	case o.CaseInsensitive != RouteMatchStrict && m.find(r.tree, path, true):
+		mode = o.CaseInsensitive
+	case toggled != "" && m.find(r.tree, toggled, false):
+		mode = o.TrailingSlash
+	case toggled != "" && o.CaseInsensitive != RouteMatchStrict && m.find(r.tree, toggled, true):
+		// both options are involved. Redirect when any of them requires redirect so the client learns canonical path.
+		mode = o.TrailingSlash
+		if mode == RouteMatchInPlace {
+			mode = o.CaseInsensitive
+		}
+	default:
+		return
+	}
+	n := m.node
+	ctx.path = n.ppath
+	ctx.pnames = n.pnames
+	copy(ctx.pvalues, m.values)
+	if mode == RouteMatchInPlace {
+		ctx.handler = m.handler
+		return
+	}
+
+	code := http.StatusMovedPermanently
+	if mode == RouteMatchPermanentRedirect {
+		code = http.StatusPermanentRedirect
+	}
+	target := string(m.canonical)
+	if len(target) > 1 && (target[1] == '/' || target[1] == '\\') {
+		// prevent redirecting to other host with scheme-relative URL e.g. `//evil.com`
+		target = "/" + strings.TrimLeft(target, `/\`)
+	}
+	ctx.handler = func(c Context) error {
+		uri := target
+		if q := c.Request().URL.RawQuery; q != "" {
+			uri += "?" + q
+		}
+		return c.Redirect(code, uri)
+	}
+}
+
+// looseMatch is recursive (backtracking) variant of the Router.Find lookup which can compare static segments ignoring
+// ASCII case. It collects param values and the canonical path with static segments spelled as registered. It is slower
+// than Router.Find and used only for paths not matching any route.
+type looseMatch struct {
+	method    string
+	fold      bool
+	canonical []byte
+	values    []string
+	node      *node
+	handler   HandlerFunc
+}
+
+func (m *looseMatch) find(root *node, path string, fold bool) bool {
+	m.fold = fold
+	m.canonical = []byte(path)
+	m.values = m.values[:0]
+	return m.match(root, path, 0)
+}
+
+func (m *looseMatch) match(n *node, search string, offset int) bool {
+	consumed := 0
+	switch n.kind {
+	case staticKind:
+		consumed = len(n.prefix)
+		if len(search) < consumed {
+			return false
+		}
+		if search[:consumed] != n.prefix && !(m.fold && equalFoldASCII(search[:consumed], n.prefix)) {
+			return false
+		}
+	case paramKind:
+		consumed = len(search)
+		if !n.isLeaf {
+			consumed = strings.IndexByte(search, '/')
+			if consumed == -1 {
+				consumed = len(search)
+			}
+		}
+		m.values = append(m.values, search[:consumed])
+	case anyKind:
+		consumed = len(search)
+		m.values = append(m.values, search)
+	}
+
+	if !m.matchChildren(n, search[consumed:], offset+consumed) {
+		if n.kind != staticKind {
+			m.values = m.values[:len(m.values)-1]
+		}
+		return false
+	}
+	if n.kind == staticKind {
+		copy(m.canonical[offset:], n.prefix)
+	}
+	return true
+}
+
+func (m *looseMatch) matchChildren(n *node, search string, offset int) bool {
+	if search == "" && n.isHandler {
+		if h := n.findHandler(m.method); h != nil {
+			m.node = n
+			m.handler = h
+			return true
+		}
+	}
+	if search != "" {
+		for _, child := range n.staticChildren {
+			if (child.label == search[0] || m.fold && lowerASCII(child.label) == lowerASCII(search[0])) &&
+				m.match(child, search, offset) {
+				return true
+			}
+		}
+		if n.paramChild != nil && m.match(n.paramChild, search, offset) {
+			return true
+		}
+	}
+	return n.anyChild != nil && m.match(n.anyChild, search, offset)
+}
+
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + ('a' - 'A')
+	}
+	return b
 }
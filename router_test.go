@@ -1,6 +1,7 @@
 package echo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -810,6 +811,404 @@ func TestRouterOptionsMethodHandler(t *testing.T) {
 	assert.Equal(t, "OPTIONS, GET", keyInContext)
 }
 
+func TestRouterCustomMethods(t *testing.T) {
+	e := New()
+	e.Match([]string{"MKCOL", "QUERY"}, "/files/:name", func(c Context) error {
+		return c.String(http.StatusOK, c.Request().Method+" "+c.Param("name"))
+	})
+	e.GET("/files/:name", func(c Context) error {
+		return c.String(http.StatusOK, "GET "+c.Param("name"))
+	})
+	e.Add("LOCK", "/files/:name", handlerFunc)
+
+	var testCases = []struct {
+		name              string
+		whenMethod        string
+		expectStatus      int
+		expectBody        string
+		expectAllowHeader string
+	}{
+		{
+			name:         "ok, custom method",
+			whenMethod:   "MKCOL",
+			expectStatus: http.StatusOK,
+			expectBody:   "MKCOL a",
+		},
+		{
+			name:         "ok, QUERY method",
+			whenMethod:   "QUERY",
+			expectStatus: http.StatusOK,
+			expectBody:   "QUERY a",
+		},
+		{
+			name:         "ok, standard method on same node",
+			whenMethod:   http.MethodGet,
+			expectStatus: http.StatusOK,
+			expectBody:   "GET a",
+		},
+		{
+			name:              "ok, OPTIONS lists custom methods",
+			whenMethod:        http.MethodOptions,
+			expectStatus:      http.StatusNoContent,
+			expectAllowHeader: "OPTIONS, GET, LOCK, MKCOL, QUERY",
+		},
+		{
+			name:              "nok, unknown method is not allowed",
+			whenMethod:        "MOVE",
+			expectStatus:      http.StatusMethodNotAllowed,
+			expectBody:        "{\"message\":\"Method Not Allowed\"}\n",
+			expectAllowHeader: "OPTIONS, GET, LOCK, MKCOL, QUERY",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.whenMethod, "/files/a", nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectAllowHeader, rec.Header().Get(HeaderAllow))
+		})
+	}
+}
+
+func TestRouterFindStandardMethodDoesNotAllocate(t *testing.T) {
+	e := New()
+	r := e.router
+	r.Add(http.MethodGet, "/users/:id", handlerFunc)
+	r.Add("PROPPATCH", "/users/:id", handlerFunc)
+	c := e.NewContext(nil, nil).(*context)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Find(http.MethodGet, "/users/1", c)
+		r.Find("PROPPATCH", "/users/1", c)
+	})
+
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestRouterLooseMatching(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		givenCase          RouteMatch
+		givenTrailingSlash RouteMatch
+		whenMethod         string
+		whenURL            string
+		expectStatus       int
+		expectBody         string
+		expectLocation     string
+	}{
+		{
+			name:         "ok, exact match",
+			givenCase:    RouteMatchInPlace,
+			whenURL:      "/users/Jon/Files",
+			expectStatus: http.StatusOK,
+			expectBody:   "/users/:id/files Jon",
+		},
+		{
+			name:         "nok, strict by default",
+			whenURL:      "/Users/Jon/FILES",
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:         "ok, case insensitive in place keeps param case",
+			givenCase:    RouteMatchInPlace,
+			whenURL:      "/Users/Jon/FILES",
+			expectStatus: http.StatusOK,
+			expectBody:   "/users/:id/files Jon",
+		},
+		{
+			name:           "ok, case insensitive redirect",
+			givenCase:      RouteMatchMovedPermanently,
+			whenURL:        "/Users/Jon/FILES?a=1",
+			expectStatus:   http.StatusMovedPermanently,
+			expectLocation: "/users/Jon/files?a=1",
+		},
+		{
+			name:         "ok, case insensitive backtracks to param route",
+			givenCase:    RouteMatchInPlace,
+			whenURL:      "/Users/new-ish/files",
+			expectStatus: http.StatusOK,
+			expectBody:   "/users/:id/files new-ish",
+		},
+		{
+			name:         "ok, case insensitive static route",
+			givenCase:    RouteMatchInPlace,
+			whenURL:      "/USERS/NEW",
+			expectStatus: http.StatusOK,
+			expectBody:   "/users/new ",
+		},
+		{
+			name:               "ok, trailing slash in place",
+			givenTrailingSlash: RouteMatchInPlace,
+			whenURL:            "/users/Jon/files/",
+			expectStatus:       http.StatusOK,
+			expectBody:         "/users/:id/files Jon",
+		},
+		{
+			name:               "ok, trailing slash removed with redirect",
+			givenTrailingSlash: RouteMatchPermanentRedirect,
+			whenMethod:         http.MethodPost,
+			whenURL:            "/users/Jon/files/",
+			expectStatus:       http.StatusPermanentRedirect,
+			expectLocation:     "/users/Jon/files",
+		},
+		{
+			name:               "ok, trailing slash added with redirect",
+			givenTrailingSlash: RouteMatchMovedPermanently,
+			whenURL:            "/docs",
+			expectStatus:       http.StatusMovedPermanently,
+			expectLocation:     "/docs/",
+		},
+		{
+			name:               "ok, both options redirect when one of them redirects",
+			givenCase:          RouteMatchInPlace,
+			givenTrailingSlash: RouteMatchMovedPermanently,
+			whenURL:            "/Users/Jon/Files/",
+			expectStatus:       http.StatusMovedPermanently,
+			expectLocation:     "/users/Jon/files",
+		},
+		{
+			name:               "ok, both options in place",
+			givenCase:          RouteMatchInPlace,
+			givenTrailingSlash: RouteMatchInPlace,
+			whenURL:            "/DOCS",
+			expectStatus:       http.StatusOK,
+			expectBody:         "/docs/ ",
+		},
+		{
+			name:               "nok, redirect does not leave host",
+			givenTrailingSlash: RouteMatchMovedPermanently,
+			whenURL:            "//evil.com",
+			expectStatus:       http.StatusMovedPermanently,
+			expectLocation:     "/evil.com/",
+		},
+		{
+			name:         "nok, method not allowed is not matched loosely",
+			givenCase:    RouteMatchInPlace,
+			whenMethod:   http.MethodPut,
+			whenURL:      "/users/new",
+			expectStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "nok, no loose match",
+			givenCase:    RouteMatchInPlace,
+			whenURL:      "/groups/1",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.Router().CaseInsensitive = tc.givenCase
+			e.Router().TrailingSlash = tc.givenTrailingSlash
+			h := func(c Context) error {
+				return c.String(http.StatusOK, c.Path()+" "+c.Param("id"))
+			}
+			e.Match([]string{http.MethodGet, http.MethodPost}, "/users/:id/files", h)
+			e.GET("/users/new", h)
+			e.GET("/docs/", h)
+			e.GET("/:a/:b/", h)
+
+			method := tc.whenMethod
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			req.URL.Path = tc.whenURL
+			if i := strings.IndexByte(tc.whenURL, '?'); i != -1 {
+				req.URL.Path, req.URL.RawQuery = tc.whenURL[:i], tc.whenURL[i+1:]
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+			assert.Equal(t, tc.expectLocation, rec.Header().Get(HeaderLocation))
+		})
+	}
+}
+
+func TestRouterLooseMatching_hostRouterUsesDefaultRouterOptions(t *testing.T) {
+	e := New()
+	h := e.Host("example.com")
+	h.GET("/users", func(c Context) error {
+		return c.String(http.StatusOK, "users")
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/Users/", nil)
+		req.Host = "example.com"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusNotFound, request().Code)
+
+	// options changed after host was added apply to host router
+	e.Router().CaseInsensitive = RouteMatchInPlace
+	e.Router().TrailingSlash = RouteMatchInPlace
+	rec := request()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "users", rec.Body.String())
+
+	e.Router().Strict = true
+	assert.Panics(t, func() {
+		h.GET("/users", handlerFunc)
+	})
+}
+
+func TestRouterTryAdd(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenMethod  string
+		whenPath    string
+		whenHandler HandlerFunc
+		expectErrIs error
+		expectErr   string
+	}{
+		{
+			name:        "ok, new route",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/users/:id/files/:name",
+			whenHandler: handlerFunc,
+		},
+		{
+			name:        "ok, same path with other method",
+			whenMethod:  http.MethodPost,
+			whenPath:    "/users/:id",
+			whenHandler: handlerFunc,
+		},
+		{
+			name:        "ok, escaped colon is not param",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/users/:id/\\:action",
+			whenHandler: handlerFunc,
+		},
+		{
+			name:        "nok, nil handler",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/new",
+			expectErrIs: ErrRouteHandlerMissing,
+			expectErr:   "echo: route GET /new: route handler is nil",
+		},
+		{
+			name:        "nok, duplicate",
+			whenMethod:  http.MethodGet,
+			whenPath:    "users/:id",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteDuplicate,
+			expectErr:   "echo: route GET /users/:id: route already registered as /users/:id",
+		},
+		{
+			name:        "nok, duplicate with different param name",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/users/:uid",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteDuplicate,
+			expectErr:   "echo: route GET /users/:uid: route already registered as /users/:id",
+		},
+		{
+			name:        "nok, conflicting param name",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/users/:uid/posts",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteParamConflict,
+			expectErr:   `echo: route GET /users/:uid/posts: conflicting route param name: "uid" differs from "id"`,
+		},
+		{
+			name:        "nok, empty param name",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/groups/:/users",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteInvalidPath,
+			expectErr:   "echo: route GET /groups/:/users: invalid route path: empty param name",
+		},
+		{
+			name:        "nok, invalid param name",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/groups/:a:b",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteInvalidPath,
+			expectErr:   `echo: route GET /groups/:a:b: invalid route path: invalid param name "a:b"`,
+		},
+		{
+			name:        "nok, duplicate param name",
+			whenMethod:  http.MethodGet,
+			whenPath:    "/groups/:id/users/:id",
+			whenHandler: handlerFunc,
+			expectErrIs: ErrRouteInvalidPath,
+			expectErr:   `echo: route GET /groups/:id/users/:id: invalid route path: duplicate param name "id"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			r := e.Router()
+			r.Add(http.MethodGet, "/users/:id", handlerFunc)
+
+			err := r.TryAdd(tc.whenMethod, tc.whenPath, tc.whenHandler)
+
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				assert.True(t, errors.Is(err, tc.expectErrIs))
+				var routeErr *RouteError
+				assert.True(t, errors.As(err, &routeErr))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRouterStrict(t *testing.T) {
+	e := New()
+	e.Router().Strict = true
+	e.GET("/users/:id", handlerFunc)
+
+	assert.PanicsWithError(t, "echo: route GET /users/:id: route already registered as /users/:id", func() {
+		e.GET("/users/:id", handlerFunc)
+	})
+	assert.PanicsWithError(t, "echo: route GET /posts: route handler is nil", func() {
+		e.GET("/posts", nil)
+	})
+	h := e.Host("strict.example.org")
+	h.GET("/a/:x", handlerFunc)
+	assert.PanicsWithError(t, `echo: route GET /a/:y/b: conflicting route param name: "y" differs from "x"`, func() {
+		h.GET("/a/:y/b", handlerFunc)
+	})
+
+	// placeholder routes registered by group middleware do not conflict with group routes
+	g := e.Group("/api", func(next HandlerFunc) HandlerFunc { return next })
+	g.Use(func(next HandlerFunc) HandlerFunc { return next })
+	g.GET("/*", func(c Context) error { return c.String(http.StatusOK, "api") })
+	g.Use(func(next HandlerFunc) HandlerFunc { return next })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/anything", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, "api", rec.Body.String())
+}
+
+func TestRouterNotStrictOverwritesRoute(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", handlerFunc)
+	e.GET("/users/:id", func(c Context) error { return c.String(http.StatusOK, "second") })
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, "second", rec.Body.String())
+}
+
 func TestRouterTwoParam(t *testing.T) {
 	e := New()
 	r := e.router
@@ -0,0 +1,41 @@
+package echo
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedSignature describes typed handler of route registered with AddTyped. It can be used for route introspection
+// and generating API documentation.
+type TypedSignature struct {
+	// Request is the type request is bound to.
+	Request reflect.Type
+	// Response is the type encoded to the response body.
+	Response reflect.Type
+	// SuccessStatus is the status code of the response when handler returns no error.
+	SuccessStatus int
+}
+
+// RouteSignature returns signature of the typed handler (see `AddTyped()`) of the route registered for host (empty
+// for routes not registered with `Echo#Host()`), method and path. Returns false when route does not exist or was not
+// registered with AddTyped.
+func (e *Echo) RouteSignature(host, method, path string) (TypedSignature, bool) {
+	info, ok := e.routeInfos[host+method+path]
+	if !ok || info.signature == nil {
+		return TypedSignature{}, false
+	}
+	return *info.signature, true
+}
+
+// String returns signature in the form of Go function type, e.g. `func(echo.Context, main.CreateUser) (main.User, error)`.
+func (s TypedSignature) String() string {
+	return fmt.Sprintf("func(echo.Context, %v) (%v, error)", s.Request, s.Response)
+}
+
+// setRouteSignature stores signature of typed handler to the route registered for host, method and path.
+func (e *Echo) setRouteSignature(host, method, path string, signature TypedSignature) {
+	if info, ok := e.routeInfos[host+method+path]; ok {
+		info.signature = &signature
+		e.routeInfos[host+method+path] = info
+	}
+}
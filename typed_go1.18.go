@@ -0,0 +1,215 @@
+//go:build go1.18
+// +build go1.18
+
+package echo
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// TypedConfig defines the config for typed handlers created with TypedWithConfig.
+	TypedConfig struct {
+		// SuccessStatus is the status code of the response when handler returns no error. With
+		// `http.StatusNoContent` the response has no body.
+		// Optional. Default value http.StatusOK.
+		SuccessStatus int
+	}
+
+	// TypedRouter is the router typed routes are registered to with AddTyped. It is implemented by *Echo and *Group.
+	TypedRouter interface {
+		addTyped(method, path string, handler HandlerFunc, signature TypedSignature, middleware ...MiddlewareFunc) *Route
+	}
+)
+
+var (
+	// DefaultTypedConfig is the default typed handler config.
+	DefaultTypedConfig = TypedConfig{
+		SuccessStatus: http.StatusOK,
+	}
+)
+
+// Typed returns handler calling function with request bound to Req and encoding returned Resp to the response.
+// See `TypedWithConfig()`.
+func Typed[Req, Resp any](fn func(c Context, in Req) (Resp, error)) HandlerFunc {
+	return TypedWithConfig(fn, DefaultTypedConfig)
+}
+
+// TypedWithConfig returns handler calling function with request bound to Req and encoding returned Resp to the
+// response.
+//
+// Request is bound with DefaultBinder from path params, query params, headers and body (in that order, each step can
+// override values of the previous one). Then request is validated with `Echo#Validator` when it is set. Binding errors
+// and validation errors (other than *HTTPError) are returned as `400 Bad Request`.
+//
+// Response is encoded as JSON or XML depending on the request `Accept` header (JSON when header is missing or any
+// type is accepted). When neither is acceptable `406 Not Acceptable` is returned. Errors returned by the function are
+// returned as is.
+//
+// Routes registered with `AddTyped()` instead of this function keep the handler signature for route introspection.
+//
+// Example:
+//
+//	e.POST("/users", echo.TypedWithConfig(func(c echo.Context, in CreateUser) (User, error) {
+//		return users.Create(c.Request().Context(), in)
+//	}, echo.TypedConfig{SuccessStatus: http.StatusCreated}))
+func TypedWithConfig[Req, Resp any](fn func(c Context, in Req) (Resp, error), config TypedConfig) HandlerFunc {
+	h, _ := typedHandler(fn, config)
+	return h
+}
+
+// AddTyped registers a new route for method and path with typed handler created from fn (see `TypedWithConfig()`)
+// to r (*Echo or *Group). Signature of the handler is stored with the route and is available with
+// `Echo#RouteSignature()`.
+//
+// Example:
+//
+//	echo.AddTyped(e, http.MethodPost, "/users", createUser, echo.TypedConfig{SuccessStatus: http.StatusCreated})
+func AddTyped[Req, Resp any](r TypedRouter, method, path string, fn func(c Context, in Req) (Resp, error), config TypedConfig, middleware ...MiddlewareFunc) *Route {
+	h, signature := typedHandler(fn, config)
+	return r.addTyped(method, path, h, signature, middleware...)
+}
+
+func (e *Echo) addTyped(method, path string, handler HandlerFunc, signature TypedSignature, middleware ...MiddlewareFunc) *Route {
+	r := e.Add(method, path, handler, middleware...)
+	e.setRouteSignature("", method, path, signature)
+	return r
+}
+
+func (g *Group) addTyped(method, path string, handler HandlerFunc, signature TypedSignature, middleware ...MiddlewareFunc) *Route {
+	r := g.Add(method, path, handler, middleware...)
+	g.echo.setRouteSignature(g.host, method, g.prefix+path, signature)
+	return r
+}
+
+// typedHandler returns handler calling fn and signature of the handler.
+func typedHandler[Req, Resp any](fn func(c Context, in Req) (Resp, error), config TypedConfig) (HandlerFunc, TypedSignature) {
+	if config.SuccessStatus == 0 {
+		config.SuccessStatus = DefaultTypedConfig.SuccessStatus
+	}
+	signature := TypedSignature{
+		Request:       reflect.TypeOf((*Req)(nil)).Elem(),
+		Response:      reflect.TypeOf((*Resp)(nil)).Elem(),
+		SuccessStatus: config.SuccessStatus,
+	}
+
+	h := func(c Context) error {
+		var in Req
+		target := interface{}(&in)
+		if signature.Request.Kind() == reflect.Ptr {
+			v := reflect.New(signature.Request.Elem())
+			reflect.ValueOf(&in).Elem().Set(v)
+			target = v.Interface()
+		}
+		if err := bindTyped(c, target); err != nil {
+			return err
+		}
+
+		out, err := fn(c, in)
+		if err != nil {
+			return err
+		}
+		return encodeTyped(c, config.SuccessStatus, out)
+	}
+	return h, signature
+}
+
+func bindTyped(c Context, target interface{}) error {
+	b := new(DefaultBinder)
+	if err := b.BindPathParams(c, target); err != nil {
+		return err
+	}
+	if err := b.BindQueryParams(c, target); err != nil {
+		return err
+	}
+	if err := b.BindHeaders(c, target); err != nil {
+		return err
+	}
+	if err := b.BindBody(c, target); err != nil {
+		return err
+	}
+
+	if c.Echo().Validator == nil {
+		return nil
+	}
+	if err := c.Validate(target); err != nil {
+		if _, ok := err.(*HTTPError); ok {
+			return err
+		}
+		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+func encodeTyped(c Context, code int, out interface{}) error {
+	if code == http.StatusNoContent {
+		return c.NoContent(code)
+	}
+	switch negotiateTypedResponse(c.Request().Header.Get(HeaderAccept)) {
+	case MIMEApplicationJSON:
+		return c.JSON(code, out)
+	case MIMEApplicationXML:
+		return c.XML(code, out)
+	default:
+		return ErrNotAcceptable
+	}
+}
+
+// negotiateTypedResponse returns the response media type (JSON or XML) preferred by Accept header or empty string
+// when none of them is acceptable. JSON wins when both are equally preferred.
+func negotiateTypedResponse(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return MIMEApplicationJSON
+	}
+	jsonQ, xmlQ := -1.0, -1.0
+	jsonSpecific, xmlSpecific := 0, 0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, q := parseAcceptPart(part)
+		specific := 1
+		var json, xml bool
+		switch mediaType {
+		case "*/*":
+			json, xml = true, true
+		case "application/*":
+			json, xml, specific = true, true, 2
+		case "text/*":
+			xml, specific = true, 2
+		case MIMEApplicationJSON:
+			json, specific = true, 3
+		case MIMEApplicationXML, "text/xml":
+			xml, specific = true, 3
+		}
+		// more specific media range takes precedence (RFC 7231 section 5.3.2)
+		if json && specific >= jsonSpecific {
+			jsonQ, jsonSpecific = q, specific
+		}
+		if xml && specific >= xmlSpecific {
+			xmlQ, xmlSpecific = q, specific
+		}
+	}
+	switch {
+	case jsonQ > 0 && jsonQ >= xmlQ:
+		return MIMEApplicationJSON
+	case xmlQ > 0:
+		return MIMEApplicationXML
+	}
+	return ""
+}
+
+func parseAcceptPart(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, p := range params[1:] {
+		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "q=") {
+			if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+				q = v
+			}
+		}
+	}
+	return mediaType, q
+}
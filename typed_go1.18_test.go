@@ -0,0 +1,206 @@
+//go:build go1.18
+// +build go1.18
+
+package echo
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type typedUserRequest struct {
+	ID      int    `param:"id"`
+	Expand  string `query:"expand"`
+	Tenant  string `header:"X-Tenant"`
+	Name    string `json:"name"`
+	Invalid bool   `json:"invalid"`
+}
+
+type typedUser struct {
+	ID     int    `json:"id" xml:"id"`
+	Name   string `json:"name" xml:"name"`
+	Tenant string `json:"tenant" xml:"tenant"`
+	Expand string `json:"expand,omitempty" xml:"expand,omitempty"`
+}
+
+type typedValidator struct{}
+
+func (typedValidator) Validate(i interface{}) error {
+	if r, ok := i.(*typedUserRequest); ok && r.Invalid {
+		return errors.New("invalid request")
+	}
+	return nil
+}
+
+func updateTypedUser(c Context, in typedUserRequest) (typedUser, error) {
+	if in.ID == 0 {
+		return typedUser{}, ErrNotFound
+	}
+	return typedUser{ID: in.ID, Name: in.Name, Tenant: in.Tenant, Expand: in.Expand}, nil
+}
+
+func TestTyped(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenConfig  TypedConfig
+		whenURL      string
+		whenAccept   string
+		whenBody     string
+		expectStatus int
+		expectBody   string
+	}{
+		{
+			name:         "ok, binds all sources and encodes JSON",
+			whenURL:      "/users/1?expand=groups",
+			whenBody:     `{"name":"Jon"}`,
+			expectStatus: http.StatusOK,
+			expectBody:   `{"id":1,"name":"Jon","tenant":"acme","expand":"groups"}` + "\n",
+		},
+		{
+			name:         "ok, custom success status",
+			givenConfig:  TypedConfig{SuccessStatus: http.StatusAccepted},
+			whenURL:      "/users/1",
+			whenBody:     `{"name":"Jon"}`,
+			expectStatus: http.StatusAccepted,
+			expectBody:   `{"id":1,"name":"Jon","tenant":"acme"}` + "\n",
+		},
+		{
+			name:         "ok, no content",
+			givenConfig:  TypedConfig{SuccessStatus: http.StatusNoContent},
+			whenURL:      "/users/1",
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:         "ok, XML is negotiated",
+			whenURL:      "/users/1",
+			whenAccept:   "application/json;q=0.5, application/xml",
+			whenBody:     `{"name":"Jon"}`,
+			expectStatus: http.StatusOK,
+			expectBody:   xml.Header + `<typedUser><id>1</id><name>Jon</name><tenant>acme</tenant></typedUser>`,
+		},
+		{
+			name:         "ok, wildcard prefers JSON",
+			whenURL:      "/users/1",
+			whenAccept:   "text/html, */*;q=0.8",
+			expectStatus: http.StatusOK,
+			expectBody:   `{"id":1,"name":"","tenant":"acme"}` + "\n",
+		},
+		{
+			name:         "nok, not acceptable",
+			whenURL:      "/users/1",
+			whenAccept:   "text/html, application/json;q=0",
+			expectStatus: http.StatusNotAcceptable,
+			expectBody:   `{"message":"Not Acceptable"}` + "\n",
+		},
+		{
+			name:         "nok, binding error",
+			whenURL:      "/users/abc",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "nok, validation error",
+			whenURL:      "/users/1",
+			whenBody:     `{"invalid":true}`,
+			expectStatus: http.StatusBadRequest,
+			expectBody:   `{"message":"invalid request"}` + "\n",
+		},
+		{
+			name:         "nok, handler error",
+			whenURL:      "/users/0",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.Validator = typedValidator{}
+			e.PUT("/users/:id", TypedWithConfig(updateTypedUser, tc.givenConfig))
+
+			req := httptest.NewRequest(http.MethodPut, tc.whenURL, strings.NewReader(tc.whenBody))
+			if tc.whenBody != "" {
+				req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			}
+			req.Header.Set("X-Tenant", "acme")
+			if tc.whenAccept != "" {
+				req.Header.Set(HeaderAccept, tc.whenAccept)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestTyped_pointerRequest(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", Typed(func(c Context, in *typedUserRequest) (*typedUser, error) {
+		return &typedUser{ID: in.ID}, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":7,"name":"","tenant":""}`+"\n", rec.Body.String())
+}
+
+func TestAddTyped(t *testing.T) {
+	e := New()
+	AddTyped(e, http.MethodPost, "/users/:id", updateTypedUser, TypedConfig{SuccessStatus: http.StatusCreated})
+	AddTyped(e, http.MethodGet, "/users/:id", func(c Context, in *typedUserRequest) ([]string, error) {
+		return nil, nil
+	}, TypedConfig{})
+	// same instantiation with other config keeps its own signature
+	AddTyped(e, http.MethodPut, "/users/:id", updateTypedUser, TypedConfig{SuccessStatus: http.StatusAccepted})
+	g := e.Group("/api")
+	route := AddTyped(g, http.MethodPost, "/users/:id", updateTypedUser, TypedConfig{})
+	e.GET("/plain", func(c Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/untyped", Typed(updateTypedUser))
+
+	signature, ok := e.RouteSignature("", http.MethodPost, "/users/:id")
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(typedUserRequest{}), signature.Request)
+	assert.Equal(t, reflect.TypeOf(typedUser{}), signature.Response)
+	assert.Equal(t, http.StatusCreated, signature.SuccessStatus)
+	assert.Equal(t, "func(echo.Context, echo.typedUserRequest) (echo.typedUser, error)", signature.String())
+
+	signature, ok = e.RouteSignature("", http.MethodGet, "/users/:id")
+	assert.True(t, ok)
+	assert.Equal(t, "func(echo.Context, *echo.typedUserRequest) ([]string, error)", signature.String())
+	assert.Equal(t, http.StatusOK, signature.SuccessStatus)
+
+	signature, ok = e.RouteSignature("", http.MethodPut, "/users/:id")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusAccepted, signature.SuccessStatus)
+
+	assert.Equal(t, "/api/users/:id", route.Path)
+	signature, ok = e.RouteSignature("", http.MethodPost, "/api/users/:id")
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusOK, signature.SuccessStatus)
+
+	req := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"name":"Jon"}`))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	_, ok = e.RouteSignature("", http.MethodGet, "/plain")
+	assert.False(t, ok)
+	_, ok = e.RouteSignature("", http.MethodGet, "/untyped")
+	assert.False(t, ok)
+	_, ok = e.RouteSignature("", http.MethodGet, "/missing")
+	assert.False(t, ok)
+}
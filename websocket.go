@@ -0,0 +1,633 @@
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// WebSocketConfig defines the config for `Context#WebSocketWithConfig`.
+	WebSocketConfig struct {
+		// CheckOrigin returns true when the WebSocket handshake from the request origin is accepted.
+		// Optional. Default accepts requests without `Origin` header, origins listed in AllowOrigins and origins
+		// with the same host as the request.
+		CheckOrigin func(c Context) bool
+
+		// AllowOrigins is the list of accepted origins (`https://example.com`) besides the same origin. Origin `*`
+		// accepts all origins. Ignored when CheckOrigin is set.
+		// Optional.
+		AllowOrigins []string
+
+		// Subprotocols lists supported subprotocols in order of preference. The first one offered by the client is
+		// selected.
+		// Optional.
+		Subprotocols []string
+
+		// EnableCompression enables negotiation of permessage-deflate extension (RFC 7692). Compression is used
+		// without context takeover so every message is compressed independently.
+		// Optional. Default value false.
+		EnableCompression bool
+
+		// ReadLimit is the maximum size of a received message in bytes (after decompression).
+		// Optional. Default value 32 MB.
+		ReadLimit int64
+
+		// PingInterval is the interval of ping frames sent to keep the connection alive. The connection fails on
+		// read when no frame is received for PingInterval + PongTimeout. Negative value disables pings.
+		// Optional. Default value 30 seconds.
+		PingInterval time.Duration
+
+		// PongTimeout is the time to wait for a frame after ping was sent.
+		// Optional. Default value 10 seconds.
+		PongTimeout time.Duration
+
+		// WriteTimeout is the maximum duration of writing a frame.
+		// Optional. Default value 10 seconds.
+		WriteTimeout time.Duration
+	}
+
+	// WebSocketMessageType is the type of a WebSocket data message.
+	WebSocketMessageType int
+
+	// WebSocketConn is a server side WebSocket connection. ReadMessage must be called from a single goroutine
+	// while WriteMessage and Close are safe for concurrent use. Control frames (ping, pong, close) are handled by
+	// ReadMessage so the connection has to be read for keepalive and close handshake to work.
+	WebSocketConn struct {
+		conn        net.Conn
+		reader      *bufio.Reader
+		echo        *Echo
+		config      WebSocketConfig
+		subprotocol string
+		compress    bool
+
+		writeMutex sync.Mutex
+		closeSent  bool
+		closeOnce  sync.Once
+		done       chan struct{}
+	}
+
+	// WebSocketCloseError is returned by `WebSocketConn#ReadMessage` when the connection was closed by the peer or
+	// due to a protocol violation.
+	WebSocketCloseError struct {
+		Code   int
+		Reason string
+	}
+
+	webSocketRegistry struct {
+		mutex sync.Mutex
+		conns map[*WebSocketConn]struct{}
+	}
+
+	webSocketFrameHeader struct {
+		fin        bool
+		compressed bool
+		opcode     byte
+		length     int64
+	}
+)
+
+// WebSocket message types
+const (
+	WebSocketText   WebSocketMessageType = 1
+	WebSocketBinary WebSocketMessageType = 2
+)
+
+// WebSocket close codes defined by RFC 6455 section 7.4.1.
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseMessageTooBig   = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+const (
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	webSocketOpContinuation = 0x0
+	webSocketOpText         = 0x1
+	webSocketOpBinary       = 0x2
+	webSocketOpClose        = 0x8
+	webSocketOpPing         = 0x9
+	webSocketOpPong         = 0xA
+
+	webSocketMaxControlPayload = 125
+)
+
+var (
+	// DefaultWebSocketConfig is the default WebSocket config.
+	DefaultWebSocketConfig = WebSocketConfig{
+		ReadLimit:    32 << 20,
+		PingInterval: 30 * time.Second,
+		PongTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	// ErrWebSocketClosed is returned when writing to a closed WebSocket connection.
+	ErrWebSocketClosed = errors.New("websocket: connection closed")
+
+	// webSocketDeflateTail is appended to compressed messages to terminate the deflate stream (RFC 7692 section
+	// 7.2.2) followed by an empty final block so the reader returns io.EOF.
+	webSocketDeflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+	webSocketFlateWriters = sync.Pool{New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	}}
+)
+
+func (c *context) WebSocket() (*WebSocketConn, error) {
+	return c.WebSocketWithConfig(DefaultWebSocketConfig)
+}
+
+func (c *context) WebSocketWithConfig(config WebSocketConfig) (*WebSocketConn, error) {
+	if config.ReadLimit == 0 {
+		config.ReadLimit = DefaultWebSocketConfig.ReadLimit
+	}
+	if config.PingInterval == 0 {
+		config.PingInterval = DefaultWebSocketConfig.PingInterval
+	}
+	if config.PongTimeout == 0 {
+		config.PongTimeout = DefaultWebSocketConfig.PongTimeout
+	}
+	if config.WriteTimeout == 0 {
+		config.WriteTimeout = DefaultWebSocketConfig.WriteTimeout
+	}
+
+	req := c.request
+	if req.Method != http.MethodGet || !headerHasToken(req.Header, HeaderConnection, "upgrade") ||
+		!headerHasToken(req.Header, HeaderUpgrade, "websocket") {
+		return nil, NewHTTPError(http.StatusBadRequest, "not a websocket handshake")
+	}
+	if req.Header.Get(HeaderSecWebSocketVersion) != "13" {
+		c.response.Header().Set(HeaderSecWebSocketVersion, "13")
+		return nil, NewHTTPError(http.StatusUpgradeRequired, "unsupported websocket version")
+	}
+	key := req.Header.Get(HeaderSecWebSocketKey)
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid websocket key")
+	}
+	if config.CheckOrigin != nil {
+		if !config.CheckOrigin(c) {
+			return nil, NewHTTPError(http.StatusForbidden, "websocket origin not allowed")
+		}
+	} else if !checkWebSocketOrigin(req, config.AllowOrigins) {
+		return nil, NewHTTPError(http.StatusForbidden, "websocket origin not allowed")
+	}
+
+	ws := &WebSocketConn{
+		echo:        c.echo,
+		config:      config,
+		subprotocol: selectWebSocketSubprotocol(req.Header, config.Subprotocols),
+		compress:    config.EnableCompression && offersPermessageDeflate(req.Header),
+		done:        make(chan struct{}),
+	}
+
+	var handshake bytes.Buffer
+	handshake.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	handshake.WriteString(HeaderSecWebSocketAccept + ": " + webSocketAccept(key) + "\r\n")
+	if ws.subprotocol != "" {
+		handshake.WriteString(HeaderSecWebSocketProtocol + ": " + ws.subprotocol + "\r\n")
+	}
+	if ws.compress {
+		handshake.WriteString(HeaderSecWebSocketExtensions +
+			": permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	handshake.WriteString("\r\n")
+
+	conn, rw, err := c.response.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// connection is no longer managed by net/http so Echo must not write the response
+	c.response.Status = http.StatusSwitchingProtocols
+	c.response.Committed = true
+
+	ws.conn = conn
+	ws.reader = rw.Reader
+	conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+	if _, err := conn.Write(handshake.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	if c.echo != nil {
+		c.echo.websockets.add(ws)
+	}
+	if config.PingInterval > 0 {
+		ws.extendReadDeadline()
+		go ws.keepalive()
+	}
+	return ws, nil
+}
+
+// Subprotocol returns the negotiated subprotocol or empty string.
+func (ws *WebSocketConn) Subprotocol() string {
+	return ws.subprotocol
+}
+
+// RemoteAddr returns the network address of the client.
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// ReadMessage reads the next data message. Ping frames are answered and close frames are replied to before
+// `*WebSocketCloseError` is returned.
+func (ws *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	var (
+		opcode     byte
+		compressed bool
+		message    []byte
+	)
+	for {
+		h, payload, err := ws.readFrame(ws.config.ReadLimit - int64(len(message)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch h.opcode {
+		case webSocketOpPing:
+			if err := ws.writeFrame(webSocketOpPong, false, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case webSocketOpPong:
+			continue
+		case webSocketOpClose:
+			return 0, nil, ws.handleClose(payload)
+		case webSocketOpText, webSocketOpBinary:
+			if opcode != 0 {
+				return 0, nil, ws.fail(WebSocketCloseProtocolError, "unfinished fragmented message")
+			}
+			opcode = h.opcode
+			compressed = h.compressed
+		case webSocketOpContinuation:
+			if opcode == 0 {
+				return 0, nil, ws.fail(WebSocketCloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, ws.fail(WebSocketCloseProtocolError, "unknown opcode")
+		}
+
+		message = append(message, payload...)
+		if h.fin {
+			break
+		}
+	}
+
+	if compressed {
+		r := flate.NewReader(io.MultiReader(bytes.NewReader(message), bytes.NewReader(webSocketDeflateTail)))
+		inflated, err := ioutil.ReadAll(io.LimitReader(r, ws.config.ReadLimit+1))
+		r.Close()
+		if err != nil {
+			return 0, nil, ws.fail(WebSocketCloseInvalidPayload, "invalid compressed message")
+		}
+		if int64(len(inflated)) > ws.config.ReadLimit {
+			return 0, nil, ws.fail(WebSocketCloseMessageTooBig, "message too big")
+		}
+		message = inflated
+	}
+	if opcode == webSocketOpText && !utf8.Valid(message) {
+		return 0, nil, ws.fail(WebSocketCloseInvalidPayload, "invalid UTF-8 in text message")
+	}
+	return WebSocketMessageType(opcode), message, nil
+}
+
+// WriteMessage writes data as a single message. Message is compressed when permessage-deflate was negotiated.
+func (ws *WebSocketConn) WriteMessage(messageType WebSocketMessageType, data []byte) error {
+	if messageType != WebSocketText && messageType != WebSocketBinary {
+		return errors.New("websocket: invalid message type")
+	}
+	if !ws.compress {
+		return ws.writeFrame(byte(messageType), false, data)
+	}
+
+	var buf bytes.Buffer
+	fw := webSocketFlateWriters.Get().(*flate.Writer)
+	fw.Reset(&buf)
+	_, err := fw.Write(data)
+	if err == nil {
+		err = fw.Flush()
+	}
+	webSocketFlateWriters.Put(fw)
+	if err != nil {
+		return err
+	}
+	// Flush ends with empty stored block which must be removed (RFC 7692 section 7.2.1)
+	return ws.writeFrame(byte(messageType), true, bytes.TrimSuffix(buf.Bytes(), webSocketDeflateTail[:4]))
+}
+
+// Close sends close frame with status 1000 (normal closure) and closes the connection.
+func (ws *WebSocketConn) Close() error {
+	return ws.CloseWithReason(WebSocketCloseNormal, "")
+}
+
+// CloseWithReason sends close frame with the code and reason and closes the connection.
+func (ws *WebSocketConn) CloseWithReason(code int, reason string) error {
+	err := ws.writeClose(code, reason)
+	ws.closeOnce.Do(func() {
+		close(ws.done)
+		if ws.echo != nil {
+			ws.echo.websockets.remove(ws)
+		}
+		if cErr := ws.conn.Close(); err == nil || err == ErrWebSocketClosed {
+			err = cErr
+		}
+	})
+	if err == ErrWebSocketClosed {
+		return nil
+	}
+	return err
+}
+
+func (ws *WebSocketConn) readFrame(limit int64) (webSocketFrameHeader, []byte, error) {
+	var h webSocketFrameHeader
+	var b [8]byte
+	if _, err := io.ReadFull(ws.reader, b[:2]); err != nil {
+		return h, nil, err
+	}
+	h.fin = b[0]&0x80 != 0
+	h.compressed = b[0]&0x40 != 0
+	h.opcode = b[0] & 0x0f
+	masked := b[1]&0x80 != 0
+	h.length = int64(b[1] & 0x7f)
+
+	if b[0]&0x30 != 0 {
+		return h, nil, ws.fail(WebSocketCloseProtocolError, "reserved bits set")
+	}
+	if h.compressed && (!ws.compress || h.opcode == webSocketOpContinuation || h.opcode >= webSocketOpClose) {
+		return h, nil, ws.fail(WebSocketCloseProtocolError, "unexpected compressed frame")
+	}
+	if !masked {
+		return h, nil, ws.fail(WebSocketCloseProtocolError, "client frame is not masked")
+	}
+
+	switch h.length {
+	case 126:
+		if _, err := io.ReadFull(ws.reader, b[:2]); err != nil {
+			return h, nil, err
+		}
+		h.length = int64(binary.BigEndian.Uint16(b[:2]))
+	case 127:
+		if _, err := io.ReadFull(ws.reader, b[:8]); err != nil {
+			return h, nil, err
+		}
+		length := binary.BigEndian.Uint64(b[:8])
+		if length > 1<<63-1 {
+			return h, nil, ws.fail(WebSocketCloseProtocolError, "invalid frame length")
+		}
+		h.length = int64(length)
+	}
+	if h.opcode >= webSocketOpClose {
+		if !h.fin || h.length > webSocketMaxControlPayload {
+			return h, nil, ws.fail(WebSocketCloseProtocolError, "invalid control frame")
+		}
+	} else if h.length > limit {
+		return h, nil, ws.fail(WebSocketCloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return h, nil, err
+	}
+	payload := make([]byte, h.length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return h, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	ws.extendReadDeadline()
+	return h, payload, nil
+}
+
+func (ws *WebSocketConn) writeFrame(opcode byte, compressed bool, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	if compressed {
+		header[0] |= 0x40
+	}
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, byte(length>>8), byte(length))
+	default:
+		header[1] = 127
+		header = header[:10]
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+	if ws.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == webSocketOpClose {
+		ws.closeSent = true
+	}
+	ws.conn.SetWriteDeadline(time.Now().Add(ws.config.WriteTimeout))
+	buffers := net.Buffers{header, payload}
+	_, err := buffers.WriteTo(ws.conn)
+	return err
+}
+
+func (ws *WebSocketConn) writeClose(code int, reason string) error {
+	if code == WebSocketCloseNoStatus {
+		return ws.writeFrame(webSocketOpClose, false, nil)
+	}
+	if len(reason) > webSocketMaxControlPayload-2 {
+		reason = reason[:webSocketMaxControlPayload-2]
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return ws.writeFrame(webSocketOpClose, false, append(payload, reason...))
+}
+
+// handleClose replies to close frame sent by the peer and closes the connection.
+func (ws *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+	switch {
+	case len(payload) == 1:
+		return ws.fail(WebSocketCloseProtocolError, "invalid close frame")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !isValidWebSocketCloseCode(closeErr.Code) || !utf8.ValidString(closeErr.Reason) {
+			return ws.fail(WebSocketCloseProtocolError, "invalid close frame")
+		}
+	}
+	ws.CloseWithReason(closeErr.Code, "")
+	return closeErr
+}
+
+// fail closes the connection with the code after protocol violation.
+func (ws *WebSocketConn) fail(code int, reason string) error {
+	ws.CloseWithReason(code, reason)
+	return &WebSocketCloseError{Code: code, Reason: reason}
+}
+
+func (ws *WebSocketConn) extendReadDeadline() {
+	if ws.config.PingInterval > 0 {
+		ws.conn.SetReadDeadline(time.Now().Add(ws.config.PingInterval + ws.config.PongTimeout))
+	}
+}
+
+func (ws *WebSocketConn) keepalive() {
+	ticker := time.NewTicker(ws.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ws.done:
+			return
+		case <-ticker.C:
+			if err := ws.writeFrame(webSocketOpPing, false, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (e *WebSocketCloseError) Error() string {
+	s := "websocket: close " + strconv.Itoa(e.Code)
+	if e.Reason != "" {
+		s += " " + e.Reason
+	}
+	return s
+}
+
+func (r *webSocketRegistry) add(ws *WebSocketConn) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.conns == nil {
+		r.conns = map[*WebSocketConn]struct{}{}
+	}
+	r.conns[ws] = struct{}{}
+}
+
+func (r *webSocketRegistry) remove(ws *WebSocketConn) {
+	r.mutex.Lock()
+	delete(r.conns, ws)
+	r.mutex.Unlock()
+}
+
+func (r *webSocketRegistry) closeAll(code int, reason string) {
+	r.mutex.Lock()
+	conns := make([]*WebSocketConn, 0, len(r.conns))
+	for ws := range r.conns {
+		conns = append(conns, ws)
+	}
+	r.mutex.Unlock()
+
+	for _, ws := range conns {
+		ws.CloseWithReason(code, reason)
+	}
+}
+
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func checkWebSocketOrigin(r *http.Request, allowOrigins []string) bool {
+	origin := r.Header.Get(HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	for _, o := range allowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func selectWebSocketSubprotocol(h http.Header, supported []string) string {
+	offered := headerTokens(h, HeaderSecWebSocketProtocol)
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// offersPermessageDeflate returns true when client offered permessage-deflate extension with parameters the server
+// is able to accept.
+func offersPermessageDeflate(h http.Header) bool {
+	for _, offer := range headerTokens(h, HeaderSecWebSocketExtensions) {
+		params := strings.Split(offer, ";")
+		if strings.TrimSpace(params[0]) != "permessage-deflate" {
+			continue
+		}
+		ok := true
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			// compress/flate always uses 32K window
+			if strings.HasPrefix(p, "server_max_window_bits=") && strings.Trim(p[23:], `"`) != "15" {
+				ok = false
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidWebSocketCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+func headerTokens(h http.Header, name string) []string {
+	var tokens []string
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerHasToken(h http.Header, name string, token string) bool {
+	for _, t := range headerTokens(h, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
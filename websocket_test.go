@@ -0,0 +1,378 @@
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	stdContext "context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testWebSocketClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	res    *http.Response
+}
+
+func dialTestWebSocket(t *testing.T, server *httptest.Server, headers map[string]string) *testWebSocketClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/ws", nil)
+	req.Header.Set(HeaderConnection, "keep-alive, Upgrade")
+	req.Header.Set(HeaderUpgrade, "websocket")
+	req.Header.Set(HeaderSecWebSocketVersion, "13")
+	req.Header.Set(HeaderSecWebSocketKey, "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	assert.NoError(t, req.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return &testWebSocketClient{conn: conn, reader: reader, res: res}
+}
+
+func (c *testWebSocketClient) writeFrame(t *testing.T, first byte, payload []byte, masked bool) {
+	frame := []byte{first, 0}
+	switch {
+	case len(payload) <= 125:
+		frame[1] = byte(len(payload))
+	case len(payload) <= 0xffff:
+		frame[1] = 126
+		frame = append(frame, byte(len(payload)>>8), byte(len(payload)))
+	default:
+		frame[1] = 127
+		frame = append(frame, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(frame[2:], uint64(len(payload)))
+	}
+	data := append([]byte{}, payload...)
+	if masked {
+		frame[1] |= 0x80
+		mask := []byte{1, 2, 3, 4}
+		frame = append(frame, mask...)
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	_, err := c.conn.Write(append(frame, data...))
+	assert.NoError(t, err)
+}
+
+func (c *testWebSocketClient) readFrame(t *testing.T) (byte, []byte) {
+	var b [8]byte
+	if _, err := io.ReadFull(c.reader, b[:2]); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Zero(t, b[1]&0x80, "server frames must not be masked")
+	length := int64(b[1] & 0x7f)
+	switch length {
+	case 126:
+		io.ReadFull(c.reader, b[:2])
+		length = int64(binary.BigEndian.Uint16(b[:2]))
+	case 127:
+		io.ReadFull(c.reader, b[:8])
+		length = int64(binary.BigEndian.Uint64(b[:8]))
+	}
+	payload := make([]byte, length)
+	_, err := io.ReadFull(c.reader, payload)
+	assert.NoError(t, err)
+	return b[0], payload
+}
+
+func newTestWebSocketServer(t *testing.T, config WebSocketConfig) (*Echo, *httptest.Server) {
+	e := New()
+	e.GET("/ws", func(c Context) error {
+		ws, err := c.WebSocketWithConfig(config)
+		if err != nil {
+			return err
+		}
+		defer ws.Close()
+		for {
+			messageType, data, err := ws.ReadMessage()
+			if err != nil {
+				return nil
+			}
+			if err := ws.WriteMessage(messageType, data); err != nil {
+				return nil
+			}
+		}
+	})
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return e, server
+}
+
+func TestContext_WebSocketHandshake(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		givenConfig       WebSocketConfig
+		whenHeaders       map[string]string
+		expectStatus      int
+		expectProtocol    string
+		expectExtensions  string
+		expectVersionHint string
+	}{
+		{
+			name:         "ok",
+			expectStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:           "ok, subprotocol negotiated in server preference",
+			givenConfig:    WebSocketConfig{Subprotocols: []string{"v2.chat", "v1.chat"}},
+			whenHeaders:    map[string]string{HeaderSecWebSocketProtocol: "v1.chat, v2.chat"},
+			expectStatus:   http.StatusSwitchingProtocols,
+			expectProtocol: "v2.chat",
+		},
+		{
+			name:         "ok, no common subprotocol",
+			givenConfig:  WebSocketConfig{Subprotocols: []string{"v2.chat"}},
+			whenHeaders:  map[string]string{HeaderSecWebSocketProtocol: "v1.chat"},
+			expectStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:             "ok, permessage-deflate",
+			givenConfig:      WebSocketConfig{EnableCompression: true},
+			whenHeaders:      map[string]string{HeaderSecWebSocketExtensions: "permessage-deflate; client_max_window_bits"},
+			expectStatus:     http.StatusSwitchingProtocols,
+			expectExtensions: "permessage-deflate; server_no_context_takeover; client_no_context_takeover",
+		},
+		{
+			name:         "ok, permessage-deflate with unsupported window is not negotiated",
+			givenConfig:  WebSocketConfig{EnableCompression: true},
+			whenHeaders:  map[string]string{HeaderSecWebSocketExtensions: "permessage-deflate; server_max_window_bits=10"},
+			expectStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "ok, same origin",
+			whenHeaders:  map[string]string{HeaderOrigin: "http://{host}"},
+			expectStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "ok, allowed origin",
+			givenConfig:  WebSocketConfig{AllowOrigins: []string{"https://example.com"}},
+			whenHeaders:  map[string]string{HeaderOrigin: "https://example.com"},
+			expectStatus: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "nok, cross origin",
+			whenHeaders:  map[string]string{HeaderOrigin: "https://evil.com"},
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name: "nok, rejected by CheckOrigin",
+			givenConfig: WebSocketConfig{CheckOrigin: func(c Context) bool {
+				return false
+			}},
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:              "nok, unsupported version",
+			whenHeaders:       map[string]string{HeaderSecWebSocketVersion: "8"},
+			expectStatus:      http.StatusUpgradeRequired,
+			expectVersionHint: "13",
+		},
+		{
+			name:         "nok, invalid key",
+			whenHeaders:  map[string]string{HeaderSecWebSocketKey: "short"},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "nok, not an upgrade",
+			whenHeaders:  map[string]string{HeaderConnection: "keep-alive"},
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, server := newTestWebSocketServer(t, tc.givenConfig)
+			headers := map[string]string{}
+			for k, v := range tc.whenHeaders {
+				headers[k] = strings.Replace(v, "{host}", strings.TrimPrefix(server.URL, "http://"), 1)
+			}
+
+			client := dialTestWebSocket(t, server, headers)
+
+			assert.Equal(t, tc.expectStatus, client.res.StatusCode)
+			assert.Equal(t, tc.expectProtocol, client.res.Header.Get(HeaderSecWebSocketProtocol))
+			assert.Equal(t, tc.expectExtensions, client.res.Header.Get(HeaderSecWebSocketExtensions))
+			assert.Equal(t, tc.expectVersionHint, client.res.Header.Get(HeaderSecWebSocketVersion))
+			if tc.expectStatus == http.StatusSwitchingProtocols {
+				assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", client.res.Header.Get(HeaderSecWebSocketAccept))
+			}
+		})
+	}
+}
+
+func TestWebSocketConn_messages(t *testing.T) {
+	_, server := newTestWebSocketServer(t, WebSocketConfig{})
+	client := dialTestWebSocket(t, server, nil)
+	assert.Equal(t, http.StatusSwitchingProtocols, client.res.StatusCode)
+
+	client.writeFrame(t, 0x81, []byte("hello"), true)
+	first, payload := client.readFrame(t)
+	assert.Equal(t, byte(0x81), first)
+	assert.Equal(t, "hello", string(payload))
+
+	// fragmented message with interleaved ping
+	client.writeFrame(t, 0x02, []byte("ab"), true)
+	client.writeFrame(t, 0x89, []byte("ping"), true)
+	client.writeFrame(t, 0x80, []byte("cd"), true)
+	first, payload = client.readFrame(t)
+	assert.Equal(t, byte(0x8A), first)
+	assert.Equal(t, "ping", string(payload))
+	first, payload = client.readFrame(t)
+	assert.Equal(t, byte(0x82), first)
+	assert.Equal(t, "abcd", string(payload))
+
+	large := bytes.Repeat([]byte("x"), 70000)
+	client.writeFrame(t, 0x82, large, true)
+	_, payload = client.readFrame(t)
+	assert.Equal(t, large, payload)
+
+	// close handshake
+	client.writeFrame(t, 0x88, []byte{0x03, 0xe8, 'b', 'y', 'e'}, true)
+	first, payload = client.readFrame(t)
+	assert.Equal(t, byte(0x88), first)
+	assert.Equal(t, []byte{0x03, 0xe8}, payload)
+	_, err := client.reader.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestWebSocketConn_compression(t *testing.T) {
+	_, server := newTestWebSocketServer(t, WebSocketConfig{EnableCompression: true})
+	client := dialTestWebSocket(t, server, map[string]string{HeaderSecWebSocketExtensions: "permessage-deflate"})
+
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	fw.Write([]byte(strings.Repeat("hello ", 100)))
+	fw.Flush()
+	client.writeFrame(t, 0xC1, bytes.TrimSuffix(buf.Bytes(), []byte{0, 0, 0xff, 0xff}), true)
+
+	first, payload := client.readFrame(t)
+	assert.Equal(t, byte(0xC1), first)
+	assert.True(t, len(payload) < 600)
+	r := flate.NewReader(io.MultiReader(bytes.NewReader(payload), bytes.NewReader(webSocketDeflateTail)))
+	message, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("hello ", 100), string(message))
+}
+
+func TestWebSocketConn_protocolErrors(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		whenFirst  byte
+		whenData   []byte
+		whenMasked bool
+		expectCode uint16
+	}{
+		{
+			name:       "unmasked frame",
+			whenFirst:  0x81,
+			whenData:   []byte("hello"),
+			expectCode: WebSocketCloseProtocolError,
+		},
+		{
+			name:       "invalid UTF-8 text",
+			whenFirst:  0x81,
+			whenData:   []byte{0xff, 0xfe},
+			whenMasked: true,
+			expectCode: WebSocketCloseInvalidPayload,
+		},
+		{
+			name:       "message too big",
+			whenFirst:  0x82,
+			whenData:   make([]byte, 200),
+			whenMasked: true,
+			expectCode: WebSocketCloseMessageTooBig,
+		},
+		{
+			name:       "compressed frame without negotiation",
+			whenFirst:  0xC2,
+			whenData:   []byte("x"),
+			whenMasked: true,
+			expectCode: WebSocketCloseProtocolError,
+		},
+		{
+			name:       "unexpected continuation",
+			whenFirst:  0x80,
+			whenData:   []byte("x"),
+			whenMasked: true,
+			expectCode: WebSocketCloseProtocolError,
+		},
+		{
+			name:       "invalid close code",
+			whenFirst:  0x88,
+			whenData:   []byte{0x03, 0xed},
+			whenMasked: true,
+			expectCode: WebSocketCloseProtocolError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, server := newTestWebSocketServer(t, WebSocketConfig{ReadLimit: 100})
+			client := dialTestWebSocket(t, server, nil)
+
+			client.writeFrame(t, tc.whenFirst, tc.whenData, tc.whenMasked)
+
+			first, payload := client.readFrame(t)
+			assert.Equal(t, byte(0x88), first)
+			if assert.True(t, len(payload) >= 2) {
+				assert.Equal(t, tc.expectCode, binary.BigEndian.Uint16(payload))
+			}
+		})
+	}
+}
+
+func TestWebSocketConn_keepalive(t *testing.T) {
+	_, server := newTestWebSocketServer(t, WebSocketConfig{PingInterval: 20 * time.Millisecond, PongTimeout: 20 * time.Millisecond})
+	client := dialTestWebSocket(t, server, nil)
+
+	first, _ := client.readFrame(t)
+	assert.Equal(t, byte(0x89), first)
+	client.writeFrame(t, 0x8A, nil, true)
+	first, _ = client.readFrame(t)
+	assert.Equal(t, byte(0x89), first)
+
+	// without pong the server stops reading and closes the connection
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := client.reader.ReadByte(); err != nil {
+			return
+		}
+	}
+	t.Fatal("connection was not closed")
+}
+
+func TestEcho_ShutdownClosesWebSockets(t *testing.T) {
+	e, server := newTestWebSocketServer(t, WebSocketConfig{})
+	client := dialTestWebSocket(t, server, nil)
+	client.writeFrame(t, 0x81, []byte("hello"), true)
+	client.readFrame(t)
+
+	assert.NoError(t, e.Shutdown(stdContext.Background()))
+
+	first, payload := client.readFrame(t)
+	assert.Equal(t, byte(0x88), first)
+	assert.Equal(t, uint16(WebSocketCloseGoingAway), binary.BigEndian.Uint16(payload))
+	assert.Equal(t, "server shutdown", string(payload[2:]))
+}